@@ -4,10 +4,15 @@ import (
 	"context"
 	"net/http"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	httpdelivery "github.com/andrewpolewoy/go_bot/cmd/bot/internal/delivery/http"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
 )
 
 type App struct {
@@ -15,6 +20,17 @@ type App struct {
 	cfg    *Config
 	server *http.Server
 	db     *pgxpool.Pool
+	outbox *service.OutboxWorker
+
+	deliveries repository.DeliveryRepository
+	messages   repository.MessageRepository
+	webhooks   *httpdelivery.Handler
+	linker     *service.Linker
+	notifier   *service.Notifier
+	reminders  *service.Reminders
+
+	bgCancel context.CancelFunc
+	bgWG     sync.WaitGroup
 }
 
 func Start() error {
@@ -22,6 +38,7 @@ func Start() error {
 	if err := a.Bootstrap(); err != nil {
 		return err
 	}
+	a.startBackground()
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net"
@@ -19,6 +21,8 @@ import (
 	appcfg "github.com/andrewpolewoy/go_bot/cmd/bot/internal/config"
 	httpdelivery "github.com/andrewpolewoy/go_bot/cmd/bot/internal/delivery/http"
 	tgdelivery "github.com/andrewpolewoy/go_bot/cmd/bot/internal/delivery/telegram"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/github"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
 	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
 	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
 )
@@ -50,7 +54,19 @@ func (a *App) Bootstrap() error {
 	a.log.Info("bootstrapping bot")
 
 	// dependencies
-	var repo repository.UserRepository
+	var (
+		repo        repository.UserRepository
+		settings    repository.SettingsRepository
+		outbox      repository.OutboxRepository
+		deliveries  repository.DeliveryRepository
+		credentials repository.CredentialsRepository
+		held        repository.HeldRepository
+		digest      repository.DigestRepository
+		prs         repository.PullRequestRepository
+		reminders   repository.ReminderRepository
+		messages    repository.MessageRepository
+		mutes       repository.MuteRepository
+	)
 
 	if rawCfg.DB.DSN != "" {
 		pool, err := pgxpool.New(context.Background(), rawCfg.DB.DSN)
@@ -60,9 +76,35 @@ func (a *App) Bootstrap() error {
 
 		a.db = pool
 		repo = pgrepo.NewUserRepo(pool)
+		settings = pgrepo.NewSettingsRepo(pool)
+		outbox = pgrepo.NewOutboxRepo(pool)
+		deliveries = pgrepo.NewDeliveryRepo(pool)
+		if rawCfg.Github.OAuth.TokenKey == "" {
+			a.log.Info("github token key is empty, stored tokens are encrypted with a key derived from the bot token")
+		}
+		credentials, err = pgrepo.NewCredentialsRepo(pool, tokenKey(rawCfg.Github.OAuth.TokenKey, rawCfg.Telegram.BotToken))
+		if err != nil {
+			return fmt.Errorf("credentials repository: %w", err)
+		}
+		held = pgrepo.NewHeldRepo(pool)
+		digest = pgrepo.NewDigestRepo(pool)
+		prs = pgrepo.NewPullRequestRepo(pool)
+		reminders = pgrepo.NewReminderRepo(pool)
+		messages = pgrepo.NewMessageRepo(pool)
+		mutes = pgrepo.NewMuteRepo(pool)
 		a.log.Info("using postgres repository")
 	} else {
 		repo = memory.NewUserRepo()
+		settings = memory.NewSettingsRepo()
+		outbox = memory.NewOutboxRepo()
+		deliveries = memory.NewDeliveryRepo()
+		credentials = memory.NewCredentialsRepo()
+		held = memory.NewHeldRepo()
+		digest = memory.NewDigestRepo()
+		prs = memory.NewPullRequestRepo()
+		reminders = memory.NewReminderRepo()
+		messages = memory.NewMessageRepo()
+		mutes = memory.NewMuteRepo()
 		a.log.Info("using memory repository")
 	}
 
@@ -72,11 +114,102 @@ func (a *App) Bootstrap() error {
 	}
 	bot.Debug = false
 
-	sender := tgdelivery.NewSender(bot)
-	svc := service.NewNotifier(repo, sender)
-	tgHandler := tgdelivery.NewHandler(svc, bot)
+	signer := tgdelivery.NewCallbackSigner(callbackSecret(rawCfg.Telegram.CallbackSecret, rawCfg.Telegram.BotToken))
+	sender := tgdelivery.NewSender(bot, signer)
+	outboxCfg := rawCfg.Outbox
+	a.outbox = service.NewOutboxWorker(outbox, messages, sender, service.OutboxConfig{
+		Workers:      outboxCfg.Workers,
+		PollInterval: outboxCfg.PollInterval,
+		BatchSize:    outboxCfg.BatchSize,
+		MaxAttempts:  outboxCfg.MaxAttempts,
+		BaseBackoff:  outboxCfg.BaseBackoff,
+		MaxBackoff:   outboxCfg.MaxBackoff,
+	}, a.log.Logger)
+
+	queued := service.NewOutboxSender(outbox)
+	recipients := rawCfg.Notify.Recipients
+	var urgent []service.EventKind
+	for _, name := range rawCfg.Notify.UrgentEvents {
+		kind, ok := service.ParseEventKind(name)
+		if !ok {
+			return fmt.Errorf("notify.urgent_events: unknown event %q", name)
+		}
+		urgent = append(urgent, kind)
+	}
+	digestTime, err := service.ParseClock(rawCfg.Notify.Digest.DefaultTime)
+	if err != nil {
+		return fmt.Errorf("notify.digest.default_time: %w", err)
+	}
+	texts, err := i18n.Load(rawCfg.I18n.Dir, rawCfg.I18n.DefaultLang)
+	if err != nil {
+		return fmt.Errorf("i18n: %w", err)
+	}
+	tracker := service.NewPullRequestTracker(prs, repo)
+	svc := service.NewNotifier(repo, settings, held, digest, mutes, queued, service.Config{
+		Teams: rawCfg.Github.Teams,
+		Recipients: service.RecipientPolicy{
+			Author:           recipients.Author,
+			Assignees:        recipients.Assignees,
+			Reviewers:        recipients.Reviewers,
+			ExcludeCommenter: recipients.ExcludeCommenter,
+		},
+		SelfNotify:      rawCfg.Notify.SelfNotify,
+		RequireVerified: rawCfg.Github.RequireVerified,
+		Urgent:          urgent,
+		DigestTime:      digestTime,
+		CommentWindow:   rawCfg.Notify.CommentWindow,
+		Texts:           texts,
+		PendingReviews:  tracker,
+	})
+	a.notifier = svc
+
+	ghClient := github.NewClient(github.Config{
+		ClientID: rawCfg.Github.OAuth.ClientID,
+		OAuthURL: rawCfg.Github.OAuth.BaseURL,
+		APIURL:   rawCfg.Github.APIURL,
+	}, nil)
+
+	if rawCfg.Github.OAuth.ClientID != "" {
+		a.linker = service.NewLinker(ghClient, repo, credentials, queued, svc, rawCfg.Github.OAuth.Scope, a.log.Logger)
+	} else {
+		a.log.Info("github oauth client id is empty, /link is disabled")
+	}
+
+	if remCfg := rawCfg.Reminders; remCfg.Enabled {
+		cal := remCfg.Calendar
+		calendar, err := service.NewWorkCalendar(cal.Timezone, cal.WorkDays, cal.WorkHours, cal.Holidays)
+		if err != nil {
+			return fmt.Errorf("reminders.calendar: %w", err)
+		}
+		if remCfg.CheckInterval <= 0 {
+			return fmt.Errorf("reminders.check_interval must be positive")
+		}
+		rules := make(map[string]service.ReminderRule, len(remCfg.Repos))
+		for name, r := range remCfg.Repos {
+			rules[name] = reminderRule(r, remCfg.Default)
+		}
+		a.reminders = service.NewReminders(prs, reminders, svc, queued, service.ReminderConfig{
+			Default:  reminderRule(remCfg.Default, appcfg.ReminderRule{}),
+			Repos:    rules,
+			Calendar: calendar,
+			Snooze:   remCfg.Snooze,
+		})
+	}
+	replier := service.NewReplier(messages, credentials, ghClient)
+	actions := service.NewPullRequestActions(messages, credentials, mutes, ghClient)
+	tgHandler := tgdelivery.NewHandler(svc, a.linker, tracker, a.reminders, replier, actions, bot, signer)
 
-	ghHandler := httpdelivery.NewHandler(svc, rawCfg.Github.Secret, a.log.Logger)
+	a.deliveries = deliveries
+	a.messages = messages
+	ci := service.NewCIMonitor(prs, svc, rawCfg.Github.CIThrottle)
+	annotator := service.NewAnnotator(messages, outbox, repo, sender, svc)
+	ghHandler := httpdelivery.NewHandler(svc, tracker, ci, annotator, deliveries, httpdelivery.Config{
+		Secret:      rawCfg.Github.Secret,
+		DeliveryTTL: rawCfg.Github.DeliveryTTL,
+		Workers:     rawCfg.Github.Workers,
+		QueueSize:   rawCfg.Github.QueueSize,
+	}, a.log.Logger)
+	a.webhooks = ghHandler
 
 	// webhook setup (Telegram)
 	if rawCfg.Server.PublicURL != "" {
@@ -116,3 +249,36 @@ func (a *App) Bootstrap() error {
 	a.log.Info("bot bootstrapped", "addr", addr)
 	return nil
 }
+
+// callbackSecret возвращает ключ подписи кнопок: из конфига или производный от токена бота,
+// чтобы сам токен не использовался как ключ напрямую.
+func callbackSecret(secret, botToken string) string {
+	if secret != "" {
+		return secret
+	}
+	sum := sha256.Sum256([]byte("callback:" + botToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// tokenKey возвращает ключ шифрования OAuth-токенов: из конфига или производный от токена бота.
+func tokenKey(key, botToken string) string {
+	if key != "" {
+		return key
+	}
+	sum := sha256.Sum256([]byte("token:" + botToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// reminderRule переводит правило из конфига; пустые поля берутся из def.
+func reminderRule(r, def appcfg.ReminderRule) service.ReminderRule {
+	if r.RemindAfter == 0 {
+		r.RemindAfter = def.RemindAfter
+	}
+	if r.EscalateAfter == 0 {
+		r.EscalateAfter = def.EscalateAfter
+	}
+	if r.LeadChatID == 0 {
+		r.LeadChatID = def.LeadChatID
+	}
+	return service.ReminderRule{RemindAfter: r.RemindAfter, EscalateAfter: r.EscalateAfter, LeadChatID: r.LeadChatID}
+}
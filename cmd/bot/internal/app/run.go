@@ -31,6 +31,166 @@ func (a *App) Run() error {
 	return nil
 }
 
+func (a *App) startBackground() {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.bgCancel = cancel
+
+	if a.outbox != nil {
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.outbox.Run(ctx)
+		}()
+	}
+
+	if a.notifier != nil {
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.flushHeld(ctx, time.Minute)
+		}()
+
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.sendDigests(ctx, time.Minute)
+		}()
+
+		if a.cfg.Raw.Notify.CommentWindow > 0 {
+			a.bgWG.Add(1)
+			go func() {
+				defer a.bgWG.Done()
+				a.flushComments(ctx, time.Second)
+			}()
+		}
+	}
+
+	if a.reminders != nil {
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.checkReminders(ctx, a.cfg.Raw.Reminders.CheckInterval)
+		}()
+	}
+
+	if a.deliveries != nil {
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.cleanupDeliveries(ctx, time.Hour)
+		}()
+	}
+
+	if a.messages != nil {
+		a.bgWG.Add(1)
+		go func() {
+			defer a.bgWG.Done()
+			a.cleanupMessages(ctx, time.Hour)
+		}()
+	}
+}
+
+func (a *App) cleanupDeliveries(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.deliveries.DeleteExpiredDeliveries(now); err != nil {
+				a.log.Error("cleanup webhook deliveries error", "err", err)
+			}
+		}
+	}
+}
+
+// cleanupMessages забывает отправленные сообщения старше telegram.message_ttl.
+func (a *App) cleanupMessages(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.messages.DeleteSentMessagesBefore(now.Add(-a.cfg.Raw.Telegram.MessageTTL)); err != nil {
+				a.log.Error("cleanup sent messages error", "err", err)
+			}
+		}
+	}
+}
+
+// flushHeld отправляет уведомления, накопленные за тихие часы.
+func (a *App) flushHeld(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.notifier.FlushHeld(now); err != nil {
+				a.log.Error("flush held notifications error", "err", err)
+			}
+		}
+	}
+}
+
+// flushComments отправляет склеенные комментарии к коду, окно которых закончилось.
+func (a *App) flushComments(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.notifier.FlushComments(now); err != nil {
+				a.log.Error("flush review comments error", "err", err)
+			}
+		}
+	}
+}
+
+// sendDigests рассылает дайджесты, время которых наступило.
+func (a *App) sendDigests(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.notifier.SendDigests(now); err != nil {
+				a.log.Error("send digests error", "err", err)
+			}
+		}
+	}
+}
+
+// checkReminders напоминает о review, которые ждут дольше порога.
+func (a *App) checkReminders(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := a.reminders.Check(now); err != nil {
+				a.log.Error("check review reminders error", "err", err)
+			}
+		}
+	}
+}
+
 func (a *App) Shutdown(timeout time.Duration) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
@@ -41,6 +201,29 @@ func (a *App) Shutdown(timeout time.Duration) error {
 		}
 	}
 
+	// сначала дорабатываем принятые webhook — они пишут в outbox
+	if a.webhooks != nil {
+		if err := a.webhooks.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
+
+	if a.linker != nil {
+		a.linker.Shutdown()
+	}
+
+	// недосклеенные комментарии не ждут конца окна
+	if a.notifier != nil {
+		if err := a.notifier.FlushComments(time.Now().Add(a.cfg.Raw.Notify.CommentWindow)); err != nil {
+			a.log.Error("flush review comments error", "err", err)
+		}
+	}
+
+	if a.bgCancel != nil {
+		a.bgCancel()
+		a.bgWG.Wait()
+	}
+
 	if a.db != nil {
 		a.db.Close()
 	}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -21,13 +22,72 @@ type Config struct {
 	} `mapstructure:"server"`
 
 	Telegram struct {
-		BotToken string `mapstructure:"bot_token"`
+		BotToken   string        `mapstructure:"bot_token"`
+		MessageTTL time.Duration `mapstructure:"message_ttl"` // сколько помнить, о чём было сообщение (ответы в GitHub)
+		// CallbackSecret — ключ подписи данных inline-кнопок; пустой — ключ выводится из токена бота.
+		CallbackSecret string `mapstructure:"callback_secret"`
 	} `mapstructure:"telegram"`
 
 	Github struct {
-		Secret string `mapstructure:"secret"`
+		Secret      string              `mapstructure:"secret"`
+		Teams       map[string][]string `mapstructure:"teams"` // slug команды -> логины участников
+		DeliveryTTL time.Duration       `mapstructure:"delivery_ttl"`
+		Workers     int                 `mapstructure:"workers"`
+		QueueSize   int                 `mapstructure:"queue_size"`
+		APIURL      string              `mapstructure:"api_url"`
+		OAuth       struct {
+			ClientID string `mapstructure:"client_id"`
+			BaseURL  string `mapstructure:"base_url"` // device code и обмен токена
+			Scope    string `mapstructure:"scope"`
+			TokenKey string `mapstructure:"token_key"` // ключ шифрования сохранённых токенов
+		} `mapstructure:"oauth"`
+		RequireVerified bool          `mapstructure:"require_verified"`
+		CIThrottle      time.Duration `mapstructure:"ci_throttle"` // не чаще одного сообщения о падении проверки коммита; учёт в памяти, рестарт его сбрасывает
 	} `mapstructure:"github"`
 
+	Notify struct {
+		Recipients struct {
+			Author           bool `mapstructure:"author"`
+			Assignees        bool `mapstructure:"assignees"`
+			Reviewers        bool `mapstructure:"reviewers"`
+			ExcludeCommenter bool `mapstructure:"exclude_commenter"`
+		} `mapstructure:"recipients"`
+		SelfNotify   bool     `mapstructure:"self_notify"`   // слать ли людям их же действия, пока они не выбрали /selfnotify
+		UrgentEvents []string `mapstructure:"urgent_events"` // приходят и в тихие часы, и в режиме дайджеста
+		Digest       struct {
+			DefaultTime string `mapstructure:"default_time"` // HH:MM в часовом поясе пользователя
+		} `mapstructure:"digest"`
+		CommentWindow time.Duration `mapstructure:"comment_window"` // склейка комментариев к коду; 0 — выкл
+	} `mapstructure:"notify"`
+
+	Reminders struct {
+		Enabled       bool                    `mapstructure:"enabled"`
+		CheckInterval time.Duration           `mapstructure:"check_interval"`
+		Default       ReminderRule            `mapstructure:"default"`
+		Repos         map[string]ReminderRule `mapstructure:"repos"`  // owner/name -> пороги; пустые поля берутся из default
+		Snooze        time.Duration           `mapstructure:"snooze"` // 0 — service.DefaultReminderSnooze
+		Calendar      struct {
+			Timezone  string   `mapstructure:"timezone"`
+			WorkDays  []string `mapstructure:"work_days"`  // mon..sun; пусто — все дни
+			WorkHours string   `mapstructure:"work_hours"` // HH:MM-HH:MM; пусто — весь день
+			Holidays  []string `mapstructure:"holidays"`   // YYYY-MM-DD
+		} `mapstructure:"calendar"`
+	} `mapstructure:"reminders"`
+
+	Outbox struct {
+		Workers      int           `mapstructure:"workers"`
+		PollInterval time.Duration `mapstructure:"poll_interval"`
+		BatchSize    int           `mapstructure:"batch_size"`
+		MaxAttempts  int           `mapstructure:"max_attempts"`
+		BaseBackoff  time.Duration `mapstructure:"base_backoff"`
+		MaxBackoff   time.Duration `mapstructure:"max_backoff"`
+	} `mapstructure:"outbox"`
+
+	I18n struct {
+		DefaultLang string `mapstructure:"default_lang"` // язык, пока пользователь не выбрал свой через /lang
+		Dir         string `mapstructure:"dir"`          // каталог с <lang>.yml поверх встроенных текстов; пусто — только встроенные
+	} `mapstructure:"i18n"`
+
 	Log struct {
 		Level string `mapstructure:"level"`
 	} `mapstructure:"log"`
@@ -37,6 +97,13 @@ type Config struct {
 	} `mapstructure:"db"`
 }
 
+// ReminderRule — пороги напоминаний о review в рабочем времени.
+type ReminderRule struct {
+	RemindAfter   time.Duration `mapstructure:"remind_after"`
+	EscalateAfter time.Duration `mapstructure:"escalate_after"`
+	LeadChatID    int64         `mapstructure:"lead_chat_id"`
+}
+
 func Load() (Config, error) {
 	v := viper.New()
 
@@ -46,6 +113,38 @@ func Load() (Config, error) {
 	v.SetDefault("server.port", 8080)
 	v.SetDefault("server.telegram_webhook_path", "/api/v1/telegram/webhook")
 	v.SetDefault("server.github_webhook_path", "/api/v1/github/webhook")
+	v.SetDefault("telegram.message_ttl", "720h")
+	v.SetDefault("github.delivery_ttl", "72h")
+	v.SetDefault("github.workers", 4)
+	v.SetDefault("github.queue_size", 100)
+	v.SetDefault("github.api_url", "https://api.github.com")
+	v.SetDefault("github.oauth.base_url", "https://github.com")
+	v.SetDefault("github.oauth.scope", "read:user")
+	v.SetDefault("github.require_verified", false)
+	v.SetDefault("github.ci_throttle", "1h")
+	v.SetDefault("notify.recipients.author", true)
+	v.SetDefault("notify.recipients.assignees", true)
+	v.SetDefault("notify.recipients.reviewers", false)
+	v.SetDefault("notify.recipients.exclude_commenter", true)
+	v.SetDefault("notify.self_notify", false)
+	v.SetDefault("notify.urgent_events", []string{})
+	v.SetDefault("notify.digest.default_time", "09:00")
+	v.SetDefault("notify.comment_window", "30s")
+	v.SetDefault("reminders.enabled", false)
+	v.SetDefault("reminders.check_interval", "5m")
+	v.SetDefault("reminders.default.remind_after", "4h")
+	v.SetDefault("reminders.default.escalate_after", "8h")
+	v.SetDefault("reminders.calendar.timezone", "UTC")
+	v.SetDefault("reminders.calendar.work_days", []string{"mon", "tue", "wed", "thu", "fri"})
+	v.SetDefault("reminders.calendar.work_hours", "10:00-19:00")
+	v.SetDefault("outbox.workers", 2)
+	v.SetDefault("outbox.poll_interval", "1s")
+	v.SetDefault("outbox.batch_size", 10)
+	v.SetDefault("outbox.max_attempts", 8)
+	v.SetDefault("outbox.base_backoff", "2s")
+	v.SetDefault("outbox.max_backoff", "10m")
+	v.SetDefault("i18n.default_lang", "ru")
+	v.SetDefault("i18n.dir", "")
 	v.SetDefault("log.level", "info")
 
 	_ = v.ReadInConfig()
@@ -56,9 +155,18 @@ func Load() (Config, error) {
 	if err := v.BindEnv("telegram.bot_token", "CRNB_TELEGRAM_BOT_TOKEN"); err != nil {
 		return Config{}, fmt.Errorf("bind env CRNB_TELEGRAM_BOT_TOKEN: %w", err)
 	}
+	if err := v.BindEnv("telegram.callback_secret", "CRNB_TELEGRAM_CALLBACK_SECRET"); err != nil {
+		return Config{}, fmt.Errorf("bind env CRNB_TELEGRAM_CALLBACK_SECRET: %w", err)
+	}
 	if err := v.BindEnv("github.secret", "CRNB_GITHUB_SECRET"); err != nil {
 		return Config{}, fmt.Errorf("bind env CRNB_GITHUB_SECRET: %w", err)
 	}
+	if err := v.BindEnv("github.oauth.client_id", "CRNB_GITHUB_OAUTH_CLIENT_ID"); err != nil {
+		return Config{}, fmt.Errorf("bind env CRNB_GITHUB_OAUTH_CLIENT_ID: %w", err)
+	}
+	if err := v.BindEnv("github.oauth.token_key", "CRNB_GITHUB_TOKEN_KEY"); err != nil {
+		return Config{}, fmt.Errorf("bind env CRNB_GITHUB_TOKEN_KEY: %w", err)
+	}
 	if err := v.BindEnv("server.public_url", "CRNB_SERVER_PUBLIC_URL"); err != nil {
 		return Config{}, fmt.Errorf("bind env CRNB_SERVER_PUBLIC_URL: %w", err)
 	}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+)
+
+// failedConclusions — итоги check_suite / check_run, о которых стоит сообщить.
+var failedConclusions = map[string]bool{
+	"failure":   true,
+	"timed_out": true,
+}
+
+type checkSuitePayload struct {
+	Action     string `json:"action"`
+	CheckSuite struct {
+		HeadSHA    string    `json:"head_sha"`
+		Conclusion string    `json:"conclusion"`
+		UpdatedAt  time.Time `json:"updated_at"`
+		RunsCount  int       `json:"latest_check_runs_count"`
+		App        struct {
+			Name string `json:"name"`
+		} `json:"app"`
+	} `json:"check_suite"`
+	Repository githubRepo `json:"repository"`
+}
+
+func (h *Handler) handleCheckSuite(body []byte) error {
+	var payload checkSuitePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("unmarshal check_suite: %w", err)
+	}
+
+	suite := payload.CheckSuite
+	if payload.Action != "completed" || !failedConclusions[suite.Conclusion] {
+		return nil
+	}
+	// о падении с check run'ами уже сообщил check_run с именем проверки — иначе
+	// одно падение пришло бы дважды: под именем проверки и под именем приложения
+	if suite.RunsCount > 0 {
+		return nil
+	}
+	// у check_suite нет своей страницы — ссылка будет на вкладку Checks PR
+	return h.reportCIFailure(service.CIFailure{
+		Repo:    payload.Repository.FullName,
+		HeadSHA: suite.HeadSHA,
+		Name:    suite.App.Name,
+		At:      suite.UpdatedAt,
+	})
+}
+
+type checkRunPayload struct {
+	Action   string `json:"action"`
+	CheckRun struct {
+		Name        string    `json:"name"`
+		HeadSHA     string    `json:"head_sha"`
+		Conclusion  string    `json:"conclusion"`
+		HTMLURL     string    `json:"html_url"`
+		DetailsURL  string    `json:"details_url"`
+		CompletedAt time.Time `json:"completed_at"`
+	} `json:"check_run"`
+	Repository githubRepo `json:"repository"`
+}
+
+func (h *Handler) handleCheckRun(body []byte) error {
+	var payload checkRunPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("unmarshal check_run: %w", err)
+	}
+
+	run := payload.CheckRun
+	if payload.Action != "completed" || !failedConclusions[run.Conclusion] {
+		return nil
+	}
+	url := run.HTMLURL
+	if url == "" {
+		url = run.DetailsURL
+	}
+	return h.reportCIFailure(service.CIFailure{
+		Repo:    payload.Repository.FullName,
+		HeadSHA: run.HeadSHA,
+		Name:    run.Name,
+		URL:     url,
+		At:      run.CompletedAt,
+	})
+}
+
+// statusPayload — legacy commit status API (внешние CI вроде Jenkins).
+type statusPayload struct {
+	SHA        string     `json:"sha"`
+	State      string     `json:"state"` // pending, success, failure, error
+	Context    string     `json:"context"`
+	TargetURL  string     `json:"target_url"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Repository githubRepo `json:"repository"`
+}
+
+func (h *Handler) handleStatus(body []byte) error {
+	var payload statusPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("unmarshal status: %w", err)
+	}
+
+	if payload.State != "failure" && payload.State != "error" {
+		return nil
+	}
+	return h.reportCIFailure(service.CIFailure{
+		Repo:    payload.Repository.FullName,
+		HeadSHA: payload.SHA,
+		Name:    payload.Context,
+		URL:     payload.TargetURL,
+		At:      payload.UpdatedAt,
+	})
+}
+
+func (h *Handler) reportCIFailure(f service.CIFailure) error {
+	if h.ci == nil {
+		return nil
+	}
+	if f.HeadSHA == "" || f.Repo == "" {
+		h.logger.Printf("[github] ci failure without repository or head sha")
+		return nil
+	}
+	if err := h.ci.ReportCIFailure(f); err != nil {
+		return fmt.Errorf("report ci failure: %w", err)
+	}
+	return nil
+}
@@ -7,29 +7,111 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
 )
 
 type Notifier interface {
-	NotifyAssignee(assigneeLogin, msg string) error
+	NotifyAssignee(assignee service.GitHubUser, n service.Notification) error
+	NotifyTeam(teamSlug string, n service.Notification) error
+	NotifyParticipants(p service.Participants, n service.Notification) error
+	NotifyAll(p service.Participants, n service.Notification) error
+	NotifyWithMentions(p service.Participants, mentioned []string, n, mention service.Notification) error
+}
+
+// PullRequestTracker сохраняет состояние PR из webhook.
+type PullRequestTracker interface {
+	TrackPullRequest(s service.PullRequestSnapshot) error
+	TrackReview(s service.PullRequestSnapshot, reviewer service.GitHubUser, state string, at time.Time) error
+	// Reviewers — все известные ревьюеры PR, включая тех, кто уже оставил review.
+	Reviewers(repo string, number int) ([]service.GitHubUser, error)
+}
+
+// CIReporter сообщает авторам PR об упавших проверках.
+type CIReporter interface {
+	ReportCIFailure(f service.CIFailure) error
+}
+
+// MessageAnnotator помечает уже отправленные уведомления, которые устарели.
+type MessageAnnotator interface {
+	AnnotatePullRequest(pr service.PullRequestRef, note string, kinds ...service.EventKind) error
+	AnnotateForUser(u service.GitHubUser, pr service.PullRequestRef, note string, kinds ...service.EventKind) error
+}
+
+// DeliveryTracker запоминает X-GitHub-Delivery, чтобы не обрабатывать повторные доставки.
+type DeliveryTracker interface {
+	MarkDelivery(id string, now time.Time, ttl time.Duration) (bool, error)
+	ForgetDelivery(id string) error
+}
+
+type Config struct {
+	Secret      string
+	DeliveryTTL time.Duration
+	Workers     int // сколько событий обрабатывается параллельно
+	QueueSize   int // сколько принятых событий может ждать обработки
 }
 
 type Handler struct {
-	notifier Notifier
-	secret   []byte
-	logger   *log.Logger
+	notifier    Notifier
+	prs         PullRequestTracker
+	ci          CIReporter
+	annotator   MessageAnnotator
+	deliveries  DeliveryTracker
+	secret      []byte
+	deliveryTTL time.Duration
+	logger      *log.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	jobs    chan webhookJob
+	workers sync.WaitGroup
 }
 
-func NewHandler(n Notifier, githubSecret string, logger *log.Logger) *Handler {
+// NewHandler создаёт обработчик GitHub webhook и запускает пул обработки событий,
+// который нужно остановить через Shutdown. prs может быть nil — тогда состояние PR
+// не сохраняется; ci может быть nil — тогда падения CI не отслеживаются; annotator может
+// быть nil — тогда старые сообщения не правятся; deliveries может быть nil — тогда
+// повторные доставки не отсекаются.
+func NewHandler(
+	n Notifier,
+	prs PullRequestTracker,
+	ci CIReporter,
+	annotator MessageAnnotator,
+	deliveries DeliveryTracker,
+	cfg Config,
+	logger *log.Logger,
+) *Handler {
 	if logger == nil {
 		logger = log.Default()
 	}
+	if cfg.DeliveryTTL <= 0 {
+		cfg.DeliveryTTL = 72 * time.Hour
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
 
-	return &Handler{
-		notifier: n,
-		secret:   []byte(strings.TrimSpace(githubSecret)),
-		logger:   logger,
+	h := &Handler{
+		notifier:    n,
+		prs:         prs,
+		ci:          ci,
+		annotator:   annotator,
+		deliveries:  deliveries,
+		secret:      []byte(strings.TrimSpace(cfg.Secret)),
+		deliveryTTL: cfg.DeliveryTTL,
+		logger:      logger,
+		jobs:        make(chan webhookJob, cfg.QueueSize),
 	}
+	h.startWorkers(cfg.Workers)
+	return h
 }
 
 func (h *Handler) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
@@ -57,223 +139,516 @@ func (h *Handler) GitHubWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	event := r.Header.Get("X-GitHub-Event")
+	deliveryID := r.Header.Get("X-GitHub-Delivery")
+	if h.isDuplicateDelivery(deliveryID) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	job := webhookJob{
+		event:      r.Header.Get("X-GitHub-Event"),
+		deliveryID: deliveryID,
+		body:       body,
+	}
+	if !h.enqueue(job) {
+		// не приняли — GitHub должен считать доставку неуспешной, а повтор не должен отсеяться
+		h.forgetDelivery(deliveryID)
+		h.logger.Printf("[github] delivery %s rejected: queue is full or shutting down", deliveryID)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
 
-	switch event {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) process(job webhookJob) error {
+	switch job.event {
 	case "pull_request":
-		h.handlePullRequest(w, body)
+		return h.handlePullRequest(job.body)
 	case "pull_request_review":
-		h.handlePullRequestReview(w, body)
+		return h.handlePullRequestReview(job.body)
 	case "pull_request_review_comment":
-		h.handlePullRequestReviewComment(w, body)
+		return h.handlePullRequestReviewComment(job.body)
+	case "issues":
+		return h.handleIssues(job.body)
+	case "issue_comment":
+		return h.handleIssueComment(job.body)
+	case "check_suite":
+		return h.handleCheckSuite(job.body)
+	case "check_run":
+		return h.handleCheckRun(job.body)
+	case "status":
+		return h.handleStatus(job.body)
 	default:
-		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+}
+
+func (h *Handler) isDuplicateDelivery(id string) bool {
+	if h.deliveries == nil || id == "" {
+		return false
+	}
+
+	first, err := h.deliveries.MarkDelivery(id, time.Now(), h.deliveryTTL)
+	if err != nil {
+		// лучше возможный дубль, чем потерянное уведомление
+		h.logger.Printf("[github] mark delivery %s error: %v", id, err)
+		return false
+	}
+	if !first {
+		h.logger.Printf("[github] duplicate delivery %s skipped", id)
+	}
+	return !first
+}
+
+func (h *Handler) forgetDelivery(id string) {
+	if h.deliveries == nil || id == "" {
+		return
+	}
+	if err := h.deliveries.ForgetDelivery(id); err != nil {
+		h.logger.Printf("[github] forget delivery %s error: %v", id, err)
 	}
 }
 
 type pullRequestPayload struct {
-	Action      string `json:"action"`
-	PullRequest struct {
-		Title   string `json:"title"`
-		HTMLURL string `json:"html_url"`
-		User    struct {
-			Login string `json:"login"`
-		} `json:"user"`
-	} `json:"pull_request"`
-	Assignee *struct {
-		Login string `json:"login"`
-	} `json:"assignee"`
-}
-
-func (h *Handler) handlePullRequest(w http.ResponseWriter, body []byte) {
+	Action            string      `json:"action"`
+	PullRequest       pullRequest `json:"pull_request"`
+	Assignee          *githubUser `json:"assignee"`
+	RequestedReviewer *githubUser `json:"requested_reviewer"`
+	RequestedTeam     *githubTeam `json:"requested_team"`
+	Repository        githubRepo  `json:"repository"`
+	Sender            githubUser  `json:"sender"`
+}
+
+type githubTeam struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func (t githubTeam) title() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.Slug
+}
+
+func (p pullRequestPayload) ref() service.PullRequestRef {
+	return p.PullRequest.ref(p.Repository)
+}
+
+func (h *Handler) handlePullRequest(body []byte) error {
 	var payload pullRequestPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
-		h.logger.Printf("[github] pull_request unmarshal error: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return fmt.Errorf("unmarshal pull_request: %w", err)
 	}
 
-	if payload.Action != "assigned" {
-		w.WriteHeader(http.StatusOK)
-		return
+	h.trackPullRequest(payload.PullRequest.snapshot(payload.Repository))
+
+	switch payload.Action {
+	case "assigned":
+		return h.notifyAssigned(payload)
+	case "review_requested", "review_request_removed":
+		return h.notifyReviewRequest(payload)
+	case "closed", "reopened", "ready_for_review", "converted_to_draft":
+		return h.notifyLifecycle(payload)
 	}
 
+	return nil
+}
+
+func (h *Handler) notifyAssigned(payload pullRequestPayload) error {
 	if payload.Assignee == nil || payload.Assignee.Login == "" {
 		h.logger.Printf("[github] assigned action without assignee")
-		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	n := service.Notification{
+		Kind:  service.EventAssigned,
+		Actor: payload.Sender.user(),
+		PR:    payload.ref(),
+		Key:   "notify.pr_assigned",
+		Args:  i18n.Args{"Title": payload.PullRequest.Title, "URL": payload.PullRequest.HTMLURL},
+	}
+	if err := h.notifier.NotifyAssignee(payload.Assignee.user(), n); err != nil {
+		return fmt.Errorf("notify assignee: %w", err)
+	}
+	return nil
+}
+
+func (h *Handler) notifyReviewRequest(payload pullRequestPayload) error {
+	// черновик ещё рано смотреть: ревьюеры узнают о нём по ready_for_review
+	if payload.PullRequest.Draft {
+		return nil
+	}
+
+	kind := service.EventReviewRequested
+	if payload.Action == "review_request_removed" {
+		kind = service.EventReviewRequestRemoved
+	}
+	removed := kind == service.EventReviewRequestRemoved
+	args := i18n.Args{"Title": payload.PullRequest.Title, "URL": payload.PullRequest.HTMLURL}
+
+	// GitHub присылает либо конкретного ревьюера, либо команду — по одному на событие.
+	switch {
+	case payload.RequestedReviewer != nil && payload.RequestedReviewer.Login != "":
+		key := "notify.review_requested"
+		if removed {
+			key = "notify.review_request_removed"
+		}
+		n := service.Notification{Kind: kind, Actor: payload.Sender.user(), PR: payload.ref(), Key: key, Args: args}
+		if err := h.notifier.NotifyAssignee(payload.RequestedReviewer.user(), n); err != nil {
+			return fmt.Errorf("notify reviewer: %w", err)
+		}
+
+	case payload.RequestedTeam != nil && payload.RequestedTeam.Slug != "":
+		key := "notify.team_review_requested"
+		if removed {
+			key = "notify.team_review_request_removed"
+		}
+		args["Team"] = payload.RequestedTeam.title()
+		n := service.Notification{Kind: kind, Actor: payload.Sender.user(), PR: payload.ref(), Key: key, Args: args}
+		if err := h.notifier.NotifyTeam(payload.RequestedTeam.Slug, n); err != nil {
+			return fmt.Errorf("notify team: %w", err)
+		}
+
+	default:
+		h.logger.Printf("[github] %s action without reviewer", payload.Action)
+	}
+	return nil
+}
+
+// notifyLifecycle сообщает автору, исполнителям и ревьюерам, что PR влит, закрыт,
+// снова открыт, готов к review или стал черновиком.
+func (h *Handler) notifyLifecycle(payload pullRequestPayload) error {
+	pr := payload.PullRequest
+
+	var (
+		key  string
+		kind service.EventKind
+	)
+	switch {
+	case payload.Action == "closed" && pr.Merged:
+		key, kind = "notify.merged", service.EventMerged
+	case payload.Action == "closed":
+		key, kind = "notify.closed", service.EventClosed
+	case payload.Action == "reopened":
+		key, kind = "notify.reopened", service.EventReopened
+	case payload.Action == "ready_for_review":
+		key, kind = "notify.ready_for_review", service.EventReadyForReview
+	default:
+		key, kind = "notify.converted_to_draft", service.EventConvertedToDraft
+	}
+
+	p := pr.participants()
+	if h.prs != nil {
+		reviewers, err := h.prs.Reviewers(payload.Repository.FullName, pr.Number)
+		if err != nil {
+			h.logger.Printf("[github] get reviewers error: %v", err)
+		}
+		p.Reviewers = append(p.Reviewers, reviewers...)
+	}
+	if kind == service.EventReadyForReview {
+		// запрошенные ревьюеры получат сам запрос review — см. replayReviewRequests
+		p.Reviewers = withoutRequested(p.Reviewers, pr.RequestedReviewers)
+	}
+
+	n := service.Notification{
+		Kind:  kind,
+		Actor: payload.Sender.user(),
+		PR:    payload.ref(),
+		Key:   key,
+		Args:  i18n.Args{"Title": pr.Title, "URL": pr.HTMLURL},
+	}
+	switch kind {
+	case service.EventMerged:
+		h.annotatePullRequest(n.PR, service.NoteMerged)
+	case service.EventClosed:
+		h.annotatePullRequest(n.PR, service.NoteClosed)
+	}
+
+	var errs []error
+	if err := h.notifier.NotifyAll(p, n); err != nil {
+		errs = append(errs, fmt.Errorf("notify participants (%s): %w", payload.Action, err))
+	}
+	if kind == service.EventReadyForReview {
+		errs = append(errs, h.replayReviewRequests(payload))
+	}
+	return errors.Join(errs...)
+}
+
+// replayReviewRequests повторяет запросы review, висящие на PR, который вышел из черновика:
+// пока он был черновиком, о них никому не сообщали.
+func (h *Handler) replayReviewRequests(payload pullRequestPayload) error {
+	pr := payload.PullRequest
+	var errs []error
+	for _, u := range pr.RequestedReviewers {
+		n := service.Notification{
+			Kind:  service.EventReviewRequested,
+			Actor: payload.Sender.user(),
+			PR:    payload.ref(),
+			Key:   "notify.review_requested",
+			Args:  i18n.Args{"Title": pr.Title, "URL": pr.HTMLURL},
+		}
+		if err := h.notifier.NotifyAssignee(u.user(), n); err != nil {
+			errs = append(errs, fmt.Errorf("notify reviewer %s: %w", u.Login, err))
+		}
+	}
+	for _, team := range pr.RequestedTeams {
+		if team.Slug == "" {
+			continue
+		}
+		n := service.Notification{
+			Kind:  service.EventReviewRequested,
+			Actor: payload.Sender.user(),
+			PR:    payload.ref(),
+			Key:   "notify.team_review_requested",
+			Args:  i18n.Args{"Title": pr.Title, "URL": pr.HTMLURL, "Team": team.title()},
+		}
+		if err := h.notifier.NotifyTeam(team.Slug, n); err != nil {
+			errs = append(errs, fmt.Errorf("notify team %s: %w", team.Slug, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// withoutRequested возвращает users без запрошенных ревьюеров.
+func withoutRequested(users []service.GitHubUser, requested []githubUser) []service.GitHubUser {
+	out := make([]service.GitHubUser, 0, len(users))
+	for _, u := range users {
+		if !slices.ContainsFunc(requested, func(r githubUser) bool { return u.Same(r.user()) }) {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+// annotatePullRequest помечает сообщения о влитом или закрытом PR, чтобы они не звали на review.
+func (h *Handler) annotatePullRequest(pr service.PullRequestRef, note string) {
+	if h.annotator == nil {
+		return
+	}
+	if err := h.annotator.AnnotatePullRequest(pr, note, service.OpenPullRequestKinds...); err != nil {
+		h.logger.Printf("[github] annotate sent messages error: %v", err)
+	}
+}
+
+func (h *Handler) trackPullRequest(s service.PullRequestSnapshot) {
+	if h.prs == nil {
+		return
+	}
+	if err := h.prs.TrackPullRequest(s); err != nil {
+		h.logger.Printf("[github] track pull request error: %v", err)
+	}
+}
+
+func (h *Handler) trackReview(s service.PullRequestSnapshot, reviewer service.GitHubUser, state string, at time.Time) {
+	if h.prs == nil {
 		return
 	}
+	if err := h.prs.TrackReview(s, reviewer, state, at); err != nil {
+		h.logger.Printf("[github] track review error: %v", err)
+	}
+}
 
-	msg := fmt.Sprintf("На вас назначен pull request: %s — %s", payload.PullRequest.Title, payload.PullRequest.HTMLURL)
-	if err := h.notifier.NotifyAssignee(payload.Assignee.Login, msg); err != nil {
+type githubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+func (u githubUser) user() service.GitHubUser {
+	return service.GitHubUser{ID: u.ID, Login: u.Login}
+}
+
+type githubRepo struct {
+	FullName string `json:"full_name"`
+}
 
-		h.logger.Printf("[github] notify assignee error: %v", err)
+type pullRequest struct {
+	ID                 int64        `json:"id"`
+	Number             int          `json:"number"`
+	Title              string       `json:"title"`
+	HTMLURL            string       `json:"html_url"`
+	User               githubUser   `json:"user"`
+	Assignees          []githubUser `json:"assignees"`
+	RequestedReviewers []githubUser `json:"requested_reviewers"`
+	RequestedTeams     []githubTeam `json:"requested_teams"`
+	Draft              bool         `json:"draft"`
+	State              string       `json:"state"`
+	Merged             bool         `json:"merged"`
+	Head               struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Mergeable *bool     `json:"mergeable"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (pr pullRequest) ref(repo githubRepo) service.PullRequestRef {
+	return service.PullRequestRef{Repo: repo.FullName, Number: pr.Number, Title: pr.Title, URL: pr.HTMLURL}
+}
+
+func (pr pullRequest) snapshot(repo githubRepo) service.PullRequestSnapshot {
+	p := pr.participants()
+	return service.PullRequestSnapshot{
+		ID:        pr.ID,
+		Ref:       pr.ref(repo),
+		Author:    p.Author,
+		Assignees: p.Assignees,
+		Reviewers: p.Reviewers,
+		Draft:     pr.Draft,
+		State:     pr.State,
+		Merged:    pr.Merged,
+		HeadSHA:   pr.Head.SHA,
+		Mergeable: pr.Mergeable,
+		CreatedAt: pr.CreatedAt,
+		UpdatedAt: pr.UpdatedAt,
 	}
+}
 
-	w.WriteHeader(http.StatusOK)
+func (pr pullRequest) participants() service.Participants {
+	p := service.Participants{Author: pr.User.user()}
+	for _, u := range pr.Assignees {
+		p.Assignees = append(p.Assignees, u.user())
+	}
+	for _, u := range pr.RequestedReviewers {
+		p.Reviewers = append(p.Reviewers, u.user())
+	}
+	return p
 }
 
 type pullRequestReviewPayload struct {
 	Action string `json:"action"`
 	Review struct {
-		State string `json:"state"`
-		Body  string `json:"body"`
+		State       string     `json:"state"`
+		Body        string     `json:"body"`
+		User        githubUser `json:"user"`
+		SubmittedAt time.Time  `json:"submitted_at"`
 	} `json:"review"`
-	PullRequest struct {
-		Title   string `json:"title"`
-		HTMLURL string `json:"html_url"`
-		User    struct {
-			Login string `json:"login"`
-		} `json:"user"`
-	} `json:"pull_request"`
-
-	Reviewer *struct {
-		Login string `json:"login"`
-	} `json:"sender"`
+	PullRequest pullRequest `json:"pull_request"`
+	Repository  githubRepo  `json:"repository"`
+	Sender      githubUser  `json:"sender"`
 }
 
-func (h *Handler) handlePullRequestReview(w http.ResponseWriter, body []byte) {
+func (h *Handler) handlePullRequestReview(body []byte) error {
 	var payload pullRequestReviewPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
-		h.logger.Printf("[github] pull_request_review unmarshal error: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		return fmt.Errorf("unmarshal pull_request_review: %w", err)
+	}
+
+	snapshot := payload.PullRequest.snapshot(payload.Repository)
+	reviewer := payload.Review.User
+	if reviewer.Login == "" {
+		reviewer = payload.Sender
+	}
+	switch payload.Action {
+	case "submitted":
+		h.trackReview(snapshot, reviewer.user(), payload.Review.State, payload.Review.SubmittedAt)
+		if h.annotator != nil {
+			err := h.annotator.AnnotateForUser(reviewer.user(), snapshot.Ref, service.NoteReviewed, service.ReviewRequestKinds...)
+			if err != nil {
+				h.logger.Printf("[github] annotate sent messages error: %v", err)
+			}
+		}
+	case "dismissed":
+		h.trackReview(snapshot, reviewer.user(), "dismissed", time.Time{})
+	default:
+		h.trackPullRequest(snapshot)
 	}
 
 	if payload.Action != "submitted" {
-		w.WriteHeader(http.StatusOK)
-		return
+		return nil
 	}
 
 	state := strings.ToLower(payload.Review.State)
 	if state == "" {
-		w.WriteHeader(http.StatusOK)
-		return
+		return nil
 	}
 
 	reviewText := trimText(payload.Review.Body, 400)
 
-	var msgPrefix string
+	var (
+		key  string
+		kind service.EventKind
+	)
 	switch state {
 	case "approved":
-		msgPrefix = "Ваш PR одобрен"
+		key = "notify.approved"
+		kind = service.EventApproved
 	case "changes_requested":
-		msgPrefix = "По вашему PR запрошены изменения"
+		key = "notify.changes_requested"
+		kind = service.EventChangesRequested
 	case "commented":
-		msgPrefix = "Новый review по вашему PR"
+		key = "notify.review_commented"
+		kind = service.EventCommented
 	default:
-		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	title := payload.PullRequest.Title
-	url := payload.PullRequest.HTMLURL
-
-	var textBuilder strings.Builder
-	textBuilder.WriteString(msgPrefix)
-	textBuilder.WriteString(": ")
-	textBuilder.WriteString(title)
-	textBuilder.WriteString(" — ")
-	textBuilder.WriteString(url)
-
-	if reviewText != "" {
-		textBuilder.WriteString("\n\nReview: ")
-		textBuilder.WriteString(reviewText)
+		return nil
 	}
 
-	// Важно: нам нужен логин assignee, а не автора review.
-	// GitHub в этом event не несёт assignee явно, поэтому:
-	// 1) либо считаем, что PR-ревью всегда автору PR (и смотрим author.login),
-	// 2) либо оставляем на потом расширение модели.
-	// Для простоты задания можно считать, что "assignee == author" и добавить поле.
-	// Если в твоей текущей модели уже есть assignee в PullRequest — используй его.
-	//
-	// Ниже — вариант с author.login, если ты решишь маппить на него.
-
-	type prWithAuthor struct {
-		User struct {
-			Login string `json:"login"`
-		} `json:"user"`
-	}
-	var prData prWithAuthor
-	_ = json.Unmarshal(body, &struct {
-		PullRequest *prWithAuthor `json:"pull_request"`
-	}{PullRequest: &prData})
-
-	assigneeLogin := strings.ToLower(payload.PullRequest.User.Login)
-	if assigneeLogin == "" {
-		w.WriteHeader(http.StatusOK)
-		return
+	n := service.Notification{
+		Kind:  kind,
+		Actor: payload.Sender.user(),
+		PR:    payload.PullRequest.ref(payload.Repository),
+		Key:   key,
+		Args:  i18n.Args{"Title": payload.PullRequest.Title, "URL": payload.PullRequest.HTMLURL, "Review": reviewText},
 	}
-
-	if err := h.notifier.NotifyAssignee(assigneeLogin, textBuilder.String()); err != nil {
-		h.logger.Printf("[github] notify assignee (review) error: %v", err)
+	mention := mentionNotification(n, "notify.mention_review")
+	mentioned := service.ParseMentions(payload.Review.Body)
+	if err := h.notifier.NotifyWithMentions(payload.PullRequest.participants(), mentioned, n, mention); err != nil {
+		return fmt.Errorf("notify participants (review): %w", err)
 	}
 
-	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 type pullRequestReviewCommentPayload struct {
 	Action  string `json:"action"`
 	Comment struct {
+		ID   int64  `json:"id"`
 		Body string `json:"body"`
 	} `json:"comment"`
-	PullRequest struct {
-		Title   string `json:"title"`
-		HTMLURL string `json:"html_url"`
-		User    struct {
-			Login string `json:"login"`
-		} `json:"user"`
-	} `json:"pull_request"`
+	PullRequest pullRequest `json:"pull_request"`
+	Repository  githubRepo  `json:"repository"`
+	Sender      githubUser  `json:"sender"`
 }
 
-func (h *Handler) handlePullRequestReviewComment(w http.ResponseWriter, body []byte) {
+func (h *Handler) handlePullRequestReviewComment(body []byte) error {
 	var payload pullRequestReviewCommentPayload
 	if err := json.Unmarshal(body, &payload); err != nil {
-		h.logger.Printf("[github] pull_request_review_comment unmarshal error: %v", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	if payload.Action != "created" {
-		w.WriteHeader(http.StatusOK)
-		return
+		return fmt.Errorf("unmarshal pull_request_review_comment: %w", err)
 	}
 
-	commentText := trimText(payload.Comment.Body, 400)
-	title := payload.PullRequest.Title
-	url := payload.PullRequest.HTMLURL
+	h.trackPullRequest(payload.PullRequest.snapshot(payload.Repository))
 
-	var sb strings.Builder
-	sb.WriteString("Новый комментарий к вашему PR: ")
-	sb.WriteString(title)
-	sb.WriteString(" — ")
-	sb.WriteString(url)
-
-	if commentText != "" {
-		sb.WriteString("\n\nКомментарий: ")
-		sb.WriteString(commentText)
+	if payload.Action != "created" {
+		return nil
 	}
 
-	type prWithAuthor struct {
-		User struct {
-			Login string `json:"login"`
-		} `json:"user"`
+	n := service.Notification{
+		Kind:  service.EventReviewComment,
+		Actor: payload.Sender.user(),
+		PR:    payload.PullRequest.ref(payload.Repository),
+		Key:   "notify.review_comment",
+		Args: i18n.Args{
+			"Title":   payload.PullRequest.Title,
+			"URL":     payload.PullRequest.HTMLURL,
+			"Comment": trimText(payload.Comment.Body, 400),
+		},
+		CommentID: payload.Comment.ID,
+		Snippet:   trimText(payload.Comment.Body, 100),
 	}
-	var prData prWithAuthor
-	_ = json.Unmarshal(body, &struct {
-		PullRequest *prWithAuthor `json:"pull_request"`
-	}{PullRequest: &prData})
-
-	assigneeLogin := strings.ToLower(payload.PullRequest.User.Login)
-	if assigneeLogin == "" {
-		w.WriteHeader(http.StatusOK)
-		return
+	mention := mentionNotification(n, "notify.mention_review_comment")
+	mentioned := service.ParseMentions(payload.Comment.Body)
+	if err := h.notifier.NotifyWithMentions(payload.PullRequest.participants(), mentioned, n, mention); err != nil {
+		return fmt.Errorf("notify participants (review_comment): %w", err)
 	}
 
-	if err := h.notifier.NotifyAssignee(assigneeLogin, sb.String()); err != nil {
-		h.logger.Printf("[github] notify assignee (review_comment) error: %v", err)
-	}
+	return nil
+}
 
-	w.WriteHeader(http.StatusOK)
+// mentionNotification — отдельное сообщение «вас упомянули» к уведомлению n
+// с теми же параметрами текста.
+func mentionNotification(n service.Notification, key string) service.Notification {
+	n.Kind = service.EventMentioned
+	n.Key = key
+	return n
 }
@@ -2,30 +2,122 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
 )
 
 type notifierMock struct {
 	calls []struct {
 		login string
 		msg   string
+		kind  service.EventKind
+	}
+	teamCalls []struct {
+		team string
+		msg  string
+	}
+	participantCalls []struct {
+		p service.Participants
+		n service.Notification
 	}
 	err error
 }
 
-func (n *notifierMock) NotifyAssignee(login, msg string) error {
+func (n *notifierMock) NotifyAssignee(u service.GitHubUser, notification service.Notification) error {
 	n.calls = append(n.calls, struct {
 		login string
 		msg   string
-	}{login: login, msg: msg})
+		kind  service.EventKind
+	}{login: u.Login, msg: rendered(notification).Text, kind: notification.Kind})
+	return n.err
+}
+
+func (n *notifierMock) NotifyTeam(team string, notification service.Notification) error {
+	n.teamCalls = append(n.teamCalls, struct {
+		team string
+		msg  string
+	}{team: team, msg: rendered(notification).Text})
+	return n.err
+}
+
+func (n *notifierMock) NotifyParticipants(p service.Participants, notification service.Notification) error {
+	n.participantCalls = append(n.participantCalls, struct {
+		p service.Participants
+		n service.Notification
+	}{p: p, n: rendered(notification)})
 	return n.err
 }
 
+func (n *notifierMock) NotifyAll(p service.Participants, notification service.Notification) error {
+	return n.NotifyParticipants(p, notification)
+}
+
+func (n *notifierMock) NotifyWithMentions(p service.Participants, mentioned []string, notification, mention service.Notification) error {
+	for _, login := range mentioned {
+		if err := n.NotifyAssignee(service.GitHubUser{Login: login}, mention); err != nil {
+			return err
+		}
+	}
+	return n.NotifyParticipants(p, notification)
+}
+
+// rendered собирает текст уведомления по-русски, как его увидит получатель.
+func rendered(n service.Notification) service.Notification {
+	if n.Key != "" {
+		n.Text = i18n.Default().Text("ru", n.Key, n.Args)
+	}
+	return n
+}
+
+type trackerMock struct {
+	snapshots []service.PullRequestSnapshot
+	reviews   []string
+	reviewers []service.GitHubUser
+}
+
+func (m *trackerMock) Reviewers(string, int) ([]service.GitHubUser, error) {
+	return m.reviewers, nil
+}
+
+func (m *trackerMock) TrackPullRequest(s service.PullRequestSnapshot) error {
+	m.snapshots = append(m.snapshots, s)
+	return nil
+}
+
+func (m *trackerMock) TrackReview(s service.PullRequestSnapshot, reviewer service.GitHubUser, state string, _ time.Time) error {
+	m.snapshots = append(m.snapshots, s)
+	m.reviews = append(m.reviews, reviewer.Login+":"+state)
+	return nil
+}
+
+type annotatorMock struct {
+	notes []string
+}
+
+func (m *annotatorMock) AnnotatePullRequest(pr service.PullRequestRef, note string, _ ...service.EventKind) error {
+	m.notes = append(m.notes, fmt.Sprintf("%s#%d %s", pr.Repo, pr.Number, note))
+	return nil
+}
+
+func (m *annotatorMock) AnnotateForUser(u service.GitHubUser, pr service.PullRequestRef, note string, _ ...service.EventKind) error {
+	m.notes = append(m.notes, fmt.Sprintf("%s %s#%d %s", u.Login, pr.Repo, pr.Number, note))
+	return nil
+}
+
 func sign(t *testing.T, secret string, body []byte) string {
 	t.Helper()
 	mac := hmac.New(sha256.New, []byte(secret))
@@ -36,7 +128,7 @@ func sign(t *testing.T, secret string, body []byte) string {
 func TestGitHubWebhook_Assigned_SendsNotification(t *testing.T) {
 	secret := "secret"
 	n := &notifierMock{}
-	h := NewHandler(n, secret, nil)
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
 
 	body := []byte(`{
 		"action":"assigned",
@@ -51,9 +143,10 @@ func TestGitHubWebhook_Assigned_SendsNotification(t *testing.T) {
 	rr := httptest.NewRecorder()
 	h.GitHubWebhook(rr, req)
 
-	if rr.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d", rr.Code)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
 	}
+	drain(t, h)
 	if len(n.calls) != 1 {
 		t.Fatalf("expected 1 notify call, got %d", len(n.calls))
 	}
@@ -65,7 +158,7 @@ func TestGitHubWebhook_Assigned_SendsNotification(t *testing.T) {
 func TestGitHubWebhook_NotAssigned_NoNotification(t *testing.T) {
 	secret := "secret"
 	n := &notifierMock{}
-	h := NewHandler(n, secret, nil)
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
 
 	body := []byte(`{
 		"action":"opened",
@@ -80,10 +173,677 @@ func TestGitHubWebhook_NotAssigned_NoNotification(t *testing.T) {
 	rr := httptest.NewRecorder()
 	h.GitHubWebhook(rr, req)
 
-	if rr.Code != http.StatusOK {
-		t.Fatalf("expected 200, got %d", rr.Code)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
 	}
+	drain(t, h)
 	if len(n.calls) != 0 {
 		t.Fatalf("expected 0 notify calls, got %d", len(n.calls))
 	}
 }
+
+// drain дожидается фоновой обработки всех принятых событий.
+func drain(t *testing.T, h *Handler) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+func postWebhook(t *testing.T, h *Handler, secret, event string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/github/webhook", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", sign(t, secret, body))
+
+	rr := httptest.NewRecorder()
+	h.GitHubWebhook(rr, req)
+	return rr
+}
+
+func TestGitHubWebhook_ReviewRequested_NotifiesReviewer(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"review_requested",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1"},
+		"requested_reviewer":{"login":"reviewer"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.calls) != 1 {
+		t.Fatalf("expected 1 notify call, got %d", len(n.calls))
+	}
+	if n.calls[0].login != "reviewer" {
+		t.Fatalf("expected login reviewer, got %q", n.calls[0].login)
+	}
+	if !strings.Contains(n.calls[0].msg, "попросили сделать review") {
+		t.Fatalf("unexpected message: %q", n.calls[0].msg)
+	}
+}
+
+func TestGitHubWebhook_ReviewRequestRemoved_NotifiesReviewer(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"review_request_removed",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1"},
+		"requested_reviewer":{"login":"reviewer"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.calls) != 1 {
+		t.Fatalf("expected 1 notify call, got %d", len(n.calls))
+	}
+	if !strings.Contains(n.calls[0].msg, "сняли запрос на review") {
+		t.Fatalf("unexpected message: %q", n.calls[0].msg)
+	}
+	// снятие запроса — отдельный тип: без кнопки Approve и со своим переключателем в /settings
+	if n.calls[0].kind != service.EventReviewRequestRemoved {
+		t.Fatalf("expected %s kind, got %s", service.EventReviewRequestRemoved, n.calls[0].kind)
+	}
+}
+
+func TestGitHubWebhook_TeamReviewRequested_NotifiesTeam(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"review_requested",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1"},
+		"requested_team":{"name":"Backend","slug":"backend"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.calls) != 0 {
+		t.Fatalf("expected 0 notify calls, got %d", len(n.calls))
+	}
+	if len(n.teamCalls) != 1 || n.teamCalls[0].team != "backend" {
+		t.Fatalf("expected 1 team call for backend, got %+v", n.teamCalls)
+	}
+}
+
+func TestGitHubWebhook_Review_PassesParticipants(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"submitted",
+		"review":{"state":"approved","body":"LGTM"},
+		"pull_request":{
+			"number":1,
+			"title":"PR title",
+			"html_url":"https://example.com/pr/1",
+			"user":{"login":"author","id":1},
+			"assignees":[{"login":"owner","id":2}],
+			"requested_reviewers":[{"login":"other","id":4}]
+		},
+		"repository":{"full_name":"org/repo"},
+		"sender":{"login":"reviewer","id":3}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request_review", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.participantCalls) != 1 {
+		t.Fatalf("expected 1 notify call, got %d", len(n.participantCalls))
+	}
+
+	p := n.participantCalls[0].p
+	if p.Author.Login != "author" || p.Author.ID != 1 {
+		t.Fatalf("unexpected author: %+v", p)
+	}
+	if actor := n.participantCalls[0].n.Actor; actor.Login != "reviewer" || actor.ID != 3 {
+		t.Fatalf("expected actor reviewer, got %+v", actor)
+	}
+	if len(p.Assignees) != 1 || p.Assignees[0].Login != "owner" || p.Assignees[0].ID != 2 {
+		t.Fatalf("unexpected assignees: %v", p.Assignees)
+	}
+	if len(p.Reviewers) != 1 || p.Reviewers[0].Login != "other" {
+		t.Fatalf("unexpected reviewers: %v", p.Reviewers)
+	}
+	if !strings.Contains(n.participantCalls[0].n.Text, "PR одобрен") {
+		t.Fatalf("unexpected message: %q", n.participantCalls[0].n.Text)
+	}
+	if n := n.participantCalls[0].n; n.Kind != service.EventApproved || n.PR.Repo != "org/repo" || n.PR.Number != 1 {
+		t.Fatalf("unexpected notification kind or PR: %+v", n)
+	}
+}
+
+func TestGitHubWebhook_ReviewComment_PassesParticipants(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"created",
+		"comment":{"id":77,"body":"nit"},
+		"pull_request":{
+			"title":"PR title",
+			"html_url":"https://example.com/pr/1",
+			"user":{"login":"author"},
+			"assignees":[{"login":"owner"}]
+		},
+		"sender":{"login":"reviewer"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request_review_comment", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.participantCalls) != 1 {
+		t.Fatalf("expected 1 notify call, got %d", len(n.participantCalls))
+	}
+	if p := n.participantCalls[0].p; p.Author.Login != "author" || len(p.Assignees) != 1 {
+		t.Fatalf("unexpected participants: %+v", p)
+	}
+	if actor := n.participantCalls[0].n.Actor; actor.Login != "reviewer" {
+		t.Fatalf("expected actor reviewer, got %+v", actor)
+	}
+	if id := n.participantCalls[0].n.CommentID; id != 77 {
+		t.Fatalf("expected comment id 77, got %d", id)
+	}
+}
+
+func TestGitHubWebhook_DuplicateDelivery_Skipped(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, memory.NewDeliveryRepo(), Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"assigned",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1"},
+		"assignee":{"login":"andrewpolewoy"}
+	}`)
+
+	wantCodes := []int{http.StatusAccepted, http.StatusOK}
+	for i := range wantCodes {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/github/webhook", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		req.Header.Set("X-Hub-Signature-256", sign(t, secret, body))
+
+		rr := httptest.NewRecorder()
+		h.GitHubWebhook(rr, req)
+		if rr.Code != wantCodes[i] {
+			t.Fatalf("request %d: expected %d, got %d", i, wantCodes[i], rr.Code)
+		}
+	}
+	drain(t, h)
+
+	if len(n.calls) != 1 {
+		t.Fatalf("expected 1 notify call, got %d", len(n.calls))
+	}
+}
+
+func TestGitHubWebhook_FailedDelivery_Redelivered(t *testing.T) {
+	secret := "secret"
+	deliveries := memory.NewDeliveryRepo()
+	body := []byte(`{
+		"action":"assigned",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1"},
+		"assignee":{"login":"andrewpolewoy"}
+	}`)
+	post := func(h *Handler) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/github/webhook", bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		req.Header.Set("X-Hub-Signature-256", sign(t, secret, body))
+		rr := httptest.NewRecorder()
+		h.GitHubWebhook(rr, req)
+		return rr.Code
+	}
+
+	failing := &notifierMock{err: errors.New("outbox is down")}
+	h := NewHandler(failing, nil, nil, nil, deliveries, Config{Secret: secret}, nil)
+	if code := post(h); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	drain(t, h)
+
+	// «Redeliver» в GitHub: обработка прошлой доставки упала, повтор должен дойти
+	n := &notifierMock{}
+	h = NewHandler(n, nil, nil, nil, deliveries, Config{Secret: secret}, nil)
+	if code := post(h); code != http.StatusAccepted {
+		t.Fatalf("expected redelivery to be accepted, got %d", code)
+	}
+	drain(t, h)
+
+	if len(n.calls) != 1 || n.calls[0].login != "andrewpolewoy" {
+		t.Fatalf("expected redelivery to be processed, got %+v", n.calls)
+	}
+}
+
+func TestGitHubWebhook_AfterShutdown_Rejected(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	deliveries := memory.NewDeliveryRepo()
+	h := NewHandler(n, nil, nil, nil, deliveries, Config{Secret: secret}, nil)
+	drain(t, h)
+
+	body := []byte(`{"action":"assigned","assignee":{"login":"andrewpolewoy"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/github/webhook", bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-GitHub-Delivery", "d1")
+	req.Header.Set("X-Hub-Signature-256", sign(t, secret, body))
+
+	rr := httptest.NewRecorder()
+	h.GitHubWebhook(rr, req)
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rr.Code)
+	}
+
+	// отклонённая доставка не должна считаться обработанной
+	first, err := deliveries.MarkDelivery("d1", time.Now(), time.Hour)
+	if err != nil || !first {
+		t.Fatalf("expected rejected delivery to be forgotten, first=%v err=%v", first, err)
+	}
+}
+
+func TestGitHubWebhook_Review_TracksState(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	tracker := &trackerMock{}
+	annotator := &annotatorMock{}
+	h := NewHandler(n, tracker, nil, annotator, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"submitted",
+		"review":{"state":"changes_requested","user":{"login":"reviewer","id":3}},
+		"pull_request":{
+			"id":100,
+			"number":5,
+			"title":"PR title",
+			"html_url":"https://example.com/pr/5",
+			"state":"open",
+			"draft":true,
+			"head":{"sha":"abc"},
+			"user":{"login":"author","id":1}
+		},
+		"repository":{"full_name":"org/repo"},
+		"sender":{"login":"reviewer","id":3}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request_review", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+
+	if len(tracker.reviews) != 1 || tracker.reviews[0] != "reviewer:changes_requested" {
+		t.Fatalf("unexpected tracked reviews: %v", tracker.reviews)
+	}
+	if len(annotator.notes) != 1 || annotator.notes[0] != "reviewer org/repo#5 "+service.NoteReviewed {
+		t.Fatalf("unexpected annotations: %v", annotator.notes)
+	}
+	s := tracker.snapshots[0]
+	if s.ID != 100 || s.Ref.Repo != "org/repo" || s.Ref.Number != 5 || !s.Draft || s.HeadSHA != "abc" {
+		t.Fatalf("unexpected snapshot: %+v", s)
+	}
+}
+
+func TestGitHubWebhook_Lifecycle_NotifiesEveryone(t *testing.T) {
+	cases := []struct {
+		action string
+		merged bool
+		kind   service.EventKind
+		note   string
+	}{
+		{"closed", true, service.EventMerged, service.NoteMerged},
+		{"closed", false, service.EventClosed, service.NoteClosed},
+		{"reopened", false, service.EventReopened, ""},
+		{"converted_to_draft", false, service.EventConvertedToDraft, ""},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.kind), func(t *testing.T) {
+			secret := "secret"
+			n := &notifierMock{}
+			tracker := &trackerMock{reviewers: []service.GitHubUser{{ID: 4, Login: "approver"}}}
+			annotator := &annotatorMock{}
+			h := NewHandler(n, tracker, nil, annotator, nil, Config{Secret: secret}, nil)
+
+			body := []byte(`{
+				"action":"` + tc.action + `",
+				"pull_request":{
+					"number":5,
+					"title":"PR title",
+					"html_url":"https://example.com/pr/5",
+					"merged":` + strconv.FormatBool(tc.merged) + `,
+					"user":{"login":"author","id":1},
+					"requested_reviewers":[{"login":"reviewer","id":3}]
+				},
+				"repository":{"full_name":"org/repo"},
+				"sender":{"login":"author","id":1}
+			}`)
+
+			rr := postWebhook(t, h, secret, "pull_request", body)
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("expected 202, got %d", rr.Code)
+			}
+			drain(t, h)
+
+			if len(n.participantCalls) != 1 {
+				t.Fatalf("expected 1 participants call, got %d", len(n.participantCalls))
+			}
+			call := n.participantCalls[0]
+			if call.n.Kind != tc.kind {
+				t.Fatalf("expected kind %s, got %s", tc.kind, call.n.Kind)
+			}
+			if call.p.Author.Login != "author" || len(call.p.Reviewers) != 2 || call.p.Reviewers[1].Login != "approver" {
+				t.Fatalf("unexpected participants: %+v", call.p)
+			}
+			switch {
+			case tc.note == "" && len(annotator.notes) != 0:
+				t.Fatalf("expected no annotations, got %v", annotator.notes)
+			case tc.note != "" && (len(annotator.notes) != 1 || annotator.notes[0] != "org/repo#5 "+tc.note):
+				t.Fatalf("unexpected annotations: %v", annotator.notes)
+			}
+		})
+	}
+}
+
+func TestGitHubWebhook_ReadyForReview_ReplaysReviewRequests(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	tracker := &trackerMock{reviewers: []service.GitHubUser{{ID: 3, Login: "reviewer"}, {ID: 4, Login: "approver"}}}
+	h := NewHandler(n, tracker, nil, nil, nil, Config{Secret: secret}, nil)
+
+	// пока PR был черновиком, запросили reviewer и команду backend — им никто не сообщил
+	body := []byte(`{
+		"action":"ready_for_review",
+		"pull_request":{
+			"number":5,
+			"title":"PR title",
+			"html_url":"https://example.com/pr/5",
+			"user":{"login":"author","id":1},
+			"requested_reviewers":[{"login":"reviewer","id":3}],
+			"requested_teams":[{"name":"Backend","slug":"backend"}]
+		},
+		"repository":{"full_name":"org/repo"},
+		"sender":{"login":"author","id":1}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+
+	if len(n.participantCalls) != 1 || n.participantCalls[0].n.Kind != service.EventReadyForReview {
+		t.Fatalf("expected ready_for_review for participants, got %+v", n.participantCalls)
+	}
+	if p := n.participantCalls[0].p; len(p.Reviewers) != 1 || p.Reviewers[0].Login != "approver" {
+		t.Fatalf("requested reviewers must get the review request instead, got %+v", p.Reviewers)
+	}
+	if len(n.calls) != 1 || n.calls[0].login != "reviewer" || n.calls[0].kind != service.EventReviewRequested {
+		t.Fatalf("expected replayed review request, got %+v", n.calls)
+	}
+	if len(n.teamCalls) != 1 || n.teamCalls[0].team != "backend" || !strings.Contains(n.teamCalls[0].msg, "Команду Backend") {
+		t.Fatalf("expected replayed team review request, got %+v", n.teamCalls)
+	}
+}
+
+func TestGitHubWebhook_DraftReviewRequested_NoNotification(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"review_requested",
+		"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1","draft":true},
+		"requested_reviewer":{"login":"reviewer"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "pull_request", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.calls) != 0 {
+		t.Fatalf("expected no notify calls for draft, got %d", len(n.calls))
+	}
+}
+
+type ciMock struct {
+	failures []service.CIFailure
+}
+
+func (m *ciMock) ReportCIFailure(f service.CIFailure) error {
+	m.failures = append(m.failures, f)
+	return nil
+}
+
+func TestGitHubWebhook_CIEvents_ReportFailures(t *testing.T) {
+	cases := []struct {
+		event  string
+		body   string
+		failed bool
+		name   string
+		url    string
+	}{
+		{
+			event:  "check_run",
+			body:   `{"action":"completed","check_run":{"name":"build","head_sha":"abc","conclusion":"failure","html_url":"https://example.com/run/1"},"repository":{"full_name":"org/repo"}}`,
+			failed: true, name: "build", url: "https://example.com/run/1",
+		},
+		{
+			event: "check_run",
+			body:  `{"action":"completed","check_run":{"name":"build","head_sha":"abc","conclusion":"success"},"repository":{"full_name":"org/repo"}}`,
+		},
+		{
+			event:  "check_suite",
+			body:   `{"action":"completed","check_suite":{"head_sha":"abc","conclusion":"timed_out","app":{"name":"GitHub Actions"}},"repository":{"full_name":"org/repo"}}`,
+			failed: true, name: "GitHub Actions",
+		},
+		{
+			event: "check_suite",
+			body:  `{"action":"requested","check_suite":{"head_sha":"abc"},"repository":{"full_name":"org/repo"}}`,
+		},
+		{
+			event:  "status",
+			body:   `{"sha":"abc","state":"error","context":"ci/jenkins","target_url":"https://ci.example.com/1","repository":{"full_name":"org/repo"}}`,
+			failed: true, name: "ci/jenkins", url: "https://ci.example.com/1",
+		},
+		{
+			event: "status",
+			body:  `{"sha":"abc","state":"pending","context":"ci/jenkins","repository":{"full_name":"org/repo"}}`,
+		},
+	}
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%s_%d", tc.event, i), func(t *testing.T) {
+			secret := "secret"
+			ci := &ciMock{}
+			h := NewHandler(&notifierMock{}, nil, ci, nil, nil, Config{Secret: secret}, nil)
+
+			rr := postWebhook(t, h, secret, tc.event, []byte(tc.body))
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("expected 202, got %d", rr.Code)
+			}
+			drain(t, h)
+
+			if !tc.failed {
+				if len(ci.failures) != 0 {
+					t.Fatalf("expected no failures, got %+v", ci.failures)
+				}
+				return
+			}
+			if len(ci.failures) != 1 {
+				t.Fatalf("expected 1 failure, got %+v", ci.failures)
+			}
+			f := ci.failures[0]
+			if f.Repo != "org/repo" || f.HeadSHA != "abc" || f.Name != tc.name || f.URL != tc.url {
+				t.Fatalf("unexpected failure: %+v", f)
+			}
+		})
+	}
+}
+
+func TestGitHubWebhook_CheckSuiteWithRuns_ReportedOnce(t *testing.T) {
+	secret := "secret"
+	ci := &ciMock{}
+	h := NewHandler(&notifierMock{}, nil, ci, nil, nil, Config{Secret: secret}, nil)
+
+	events := []struct{ event, body string }{
+		{"check_run", `{"action":"completed","check_run":{"name":"build","head_sha":"abc","conclusion":"failure"},"repository":{"full_name":"org/repo"}}`},
+		{"check_suite", `{"action":"completed","check_suite":{"head_sha":"abc","conclusion":"failure","latest_check_runs_count":1,"app":{"name":"GitHub Actions"}},"repository":{"full_name":"org/repo"}}`},
+	}
+	for _, e := range events {
+		if rr := postWebhook(t, h, secret, e.event, []byte(e.body)); rr.Code != http.StatusAccepted {
+			t.Fatalf("expected 202, got %d", rr.Code)
+		}
+	}
+	drain(t, h)
+
+	if len(ci.failures) != 1 || ci.failures[0].Name != "build" {
+		t.Fatalf("expected one failure for the check run, got %+v", ci.failures)
+	}
+}
+
+func TestGitHubWebhook_IssueAssigned_NotifiesAssignee(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"assigned",
+		"issue":{"number":3,"title":"Bug","html_url":"https://example.com/issues/3"},
+		"assignee":{"login":"alice"},
+		"repository":{"full_name":"org/repo"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "issues", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.calls) != 1 || n.calls[0].login != "alice" {
+		t.Fatalf("expected alice to be notified, got %+v", n.calls)
+	}
+}
+
+func TestGitHubWebhook_IssueClosed_NotifiesParticipants(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"closed",
+		"issue":{"number":3,"title":"Bug","user":{"login":"author"},"assignees":[{"login":"alice"}]},
+		"repository":{"full_name":"org/repo"},
+		"sender":{"login":"bob"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "issues", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+	if len(n.participantCalls) != 1 {
+		t.Fatalf("expected 1 participants call, got %d", len(n.participantCalls))
+	}
+	call := n.participantCalls[0]
+	if call.n.Kind != service.EventIssueClosed || !call.n.Issue || call.p.Author.Login != "author" || len(call.p.Assignees) != 1 {
+		t.Fatalf("unexpected call: %+v", call)
+	}
+}
+
+func TestGitHubWebhook_IssueComment_NotifiesMentioned(t *testing.T) {
+	secret := "secret"
+	n := &notifierMock{}
+	tracker := &trackerMock{reviewers: []service.GitHubUser{{Login: "reviewer"}}}
+	h := NewHandler(n, tracker, nil, nil, nil, Config{Secret: secret}, nil)
+
+	body := []byte(`{
+		"action":"created",
+		"issue":{"number":5,"title":"PR title","user":{"login":"author"},"pull_request":{}},
+		"comment":{"body":"@alice @carol please look","html_url":"https://example.com/pr/5#issuecomment-1"},
+		"repository":{"full_name":"org/repo"},
+		"sender":{"login":"bob"}
+	}`)
+
+	rr := postWebhook(t, h, secret, "issue_comment", body)
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rr.Code)
+	}
+	drain(t, h)
+
+	if len(n.calls) != 2 || n.calls[0].login != "alice" || n.calls[1].login != "carol" {
+		t.Fatalf("expected alice and carol to be mentioned, got %+v", n.calls)
+	}
+	if !strings.Contains(n.calls[0].msg, "Вас упомянули в комментарии к PR") {
+		t.Fatalf("unexpected mention text: %q", n.calls[0].msg)
+	}
+	if len(n.participantCalls) != 1 {
+		t.Fatalf("expected 1 participants call, got %d", len(n.participantCalls))
+	}
+	call := n.participantCalls[0]
+	// комментарий в обсуждении PR — это уведомление о PR, кнопки PR под ним уместны
+	if call.n.Kind != service.EventIssueComment || call.n.Issue || call.p.Author.Login != "author" || len(call.p.Reviewers) != 1 {
+		t.Fatalf("unexpected call: %+v", call)
+	}
+}
+
+func TestGitHubWebhook_ReviewMentions_NotifyMentioned(t *testing.T) {
+	cases := []struct {
+		event, body, prefix string
+	}{
+		{
+			event: "pull_request_review",
+			body: `{"action":"submitted","review":{"state":"commented","body":"@alice can you check this?\n> @bob said"},
+				"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1","user":{"login":"author"}},"sender":{"login":"reviewer"}}`,
+			prefix: "Вас упомянули в review",
+		},
+		{
+			event: "pull_request_review_comment",
+			body: "{\"action\":\"created\",\"comment\":{\"body\":\"@alice `@bob` here\"}," +
+				`"pull_request":{"title":"PR title","html_url":"https://example.com/pr/1","user":{"login":"author"}},"sender":{"login":"reviewer"}}`,
+			prefix: "Вас упомянули в комментарии к коду",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.event, func(t *testing.T) {
+			secret := "secret"
+			n := &notifierMock{}
+			h := NewHandler(n, nil, nil, nil, nil, Config{Secret: secret}, nil)
+
+			rr := postWebhook(t, h, secret, tc.event, []byte(tc.body))
+			if rr.Code != http.StatusAccepted {
+				t.Fatalf("expected 202, got %d", rr.Code)
+			}
+			drain(t, h)
+
+			if len(n.calls) != 1 || n.calls[0].login != "alice" {
+				t.Fatalf("expected only alice to be mentioned, got %+v", n.calls)
+			}
+			if !strings.HasPrefix(n.calls[0].msg, tc.prefix) {
+				t.Fatalf("unexpected mention text: %q", n.calls[0].msg)
+			}
+			if len(n.participantCalls) != 1 {
+				t.Fatalf("expected 1 participants call, got %d", len(n.participantCalls))
+			}
+		})
+	}
+}
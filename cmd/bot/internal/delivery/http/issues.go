@@ -0,0 +1,140 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+)
+
+type issue struct {
+	Number      int          `json:"number"`
+	Title       string       `json:"title"`
+	HTMLURL     string       `json:"html_url"`
+	User        githubUser   `json:"user"`
+	Assignees   []githubUser `json:"assignees"`
+	PullRequest *struct{}    `json:"pull_request"` // есть, если это обсуждение PR
+}
+
+func (i issue) ref(repo githubRepo) service.PullRequestRef {
+	return service.PullRequestRef{Repo: repo.FullName, Number: i.Number, Title: i.Title, URL: i.HTMLURL}
+}
+
+func (i issue) participants() service.Participants {
+	p := service.Participants{Author: i.User.user()}
+	for _, u := range i.Assignees {
+		p.Assignees = append(p.Assignees, u.user())
+	}
+	return p
+}
+
+type issuesPayload struct {
+	Action     string      `json:"action"`
+	Issue      issue       `json:"issue"`
+	Assignee   *githubUser `json:"assignee"`
+	Repository githubRepo  `json:"repository"`
+	Sender     githubUser  `json:"sender"`
+}
+
+func (h *Handler) handleIssues(body []byte) error {
+	var payload issuesPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("unmarshal issues: %w", err)
+	}
+
+	is := payload.Issue
+	switch payload.Action {
+	case "assigned":
+		if payload.Assignee == nil || payload.Assignee.Login == "" {
+			h.logger.Printf("[github] issue assigned action without assignee")
+			return nil
+		}
+		n := service.Notification{
+			Kind:  service.EventIssueAssigned,
+			Actor: payload.Sender.user(),
+			PR:    is.ref(payload.Repository),
+			Key:   "notify.issue_assigned",
+			Args:  i18n.Args{"Title": is.Title, "URL": is.HTMLURL},
+			Issue: true,
+		}
+		if err := h.notifier.NotifyAssignee(payload.Assignee.user(), n); err != nil {
+			return fmt.Errorf("notify issue assignee: %w", err)
+		}
+
+	case "closed":
+		n := service.Notification{
+			Kind:  service.EventIssueClosed,
+			Actor: payload.Sender.user(),
+			PR:    is.ref(payload.Repository),
+			Key:   "notify.issue_closed",
+			Args:  i18n.Args{"Title": is.Title, "URL": is.HTMLURL},
+			Issue: true,
+		}
+		if err := h.notifier.NotifyAll(is.participants(), n); err != nil {
+			return fmt.Errorf("notify participants (issue closed): %w", err)
+		}
+	}
+	return nil
+}
+
+type issueCommentPayload struct {
+	Action  string `json:"action"`
+	Issue   issue  `json:"issue"`
+	Comment struct {
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+	} `json:"comment"`
+	Repository githubRepo `json:"repository"`
+	Sender     githubUser `json:"sender"`
+}
+
+// handleIssueComment обрабатывает комментарии к issue и к обсуждению PR:
+// GitHub присылает их одним событием.
+func (h *Handler) handleIssueComment(body []byte) error {
+	var payload issueCommentPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("unmarshal issue_comment: %w", err)
+	}
+	if payload.Action != "created" {
+		return nil
+	}
+
+	is := payload.Issue
+	p := is.participants()
+	if is.PullRequest != nil {
+		if h.prs != nil {
+			reviewers, err := h.prs.Reviewers(payload.Repository.FullName, is.Number)
+			if err != nil {
+				h.logger.Printf("[github] get reviewers error: %v", err)
+			}
+			p.Reviewers = reviewers
+		}
+	}
+
+	url := is.HTMLURL
+	if payload.Comment.HTMLURL != "" {
+		url = payload.Comment.HTMLURL
+	}
+
+	n := service.Notification{
+		Kind:  service.EventIssueComment,
+		Actor: payload.Sender.user(),
+		PR:    is.ref(payload.Repository),
+		Key:   "notify.issue_comment",
+		Args: i18n.Args{
+			"IsPR":    is.PullRequest != nil,
+			"Title":   is.Title,
+			"URL":     url,
+			"Comment": trimText(payload.Comment.Body, 400),
+		},
+		Issue: is.PullRequest == nil,
+	}
+	mention := mentionNotification(n, "notify.mention_issue_comment")
+
+	mentioned := service.ParseMentions(payload.Comment.Body)
+	if err := h.notifier.NotifyWithMentions(p, mentioned, n, mention); err != nil {
+		return fmt.Errorf("notify participants (issue comment): %w", err)
+	}
+	return nil
+}
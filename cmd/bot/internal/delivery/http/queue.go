@@ -0,0 +1,64 @@
+package http
+
+import "context"
+
+type webhookJob struct {
+	event      string
+	deliveryID string
+	body       []byte
+}
+
+func (h *Handler) startWorkers(n int) {
+	for i := 0; i < n; i++ {
+		h.workers.Add(1)
+		go func() {
+			defer h.workers.Done()
+			for job := range h.jobs {
+				if err := h.process(job); err != nil {
+					// доставка не обработана: «Redeliver» в GitHub не должен отсеяться как дубль
+					h.forgetDelivery(job.deliveryID)
+					h.logger.Printf("[github] %s delivery %s error: %v", job.event, job.deliveryID, err)
+				}
+			}
+		}()
+	}
+}
+
+// enqueue не блокируется: если очередь заполнена или обработчик остановлен, возвращает false.
+func (h *Handler) enqueue(job webhookJob) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.closed {
+		return false
+	}
+	select {
+	case h.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown перестаёт принимать события и дожидается обработки уже принятых.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.jobs)
+	}
+	h.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
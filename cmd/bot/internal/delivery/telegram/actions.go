@@ -0,0 +1,52 @@
+package telegram
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleActionCallback выполняет кнопки "Заглушить" и "Approve" под уведомлением о PR.
+func (h *Handler) handleActionCallback(cq *tgbotapi.CallbackQuery) {
+	chatID := cq.Message.Chat.ID
+	answer := func(key string, args i18n.Args) {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, key, args)))
+	}
+	if h.actions == nil {
+		answer("action.unavailable", nil)
+		return
+	}
+
+	var (
+		ref  service.PullRequestRef
+		err  error
+		done string
+	)
+	if fingerprint, ok := strings.CutPrefix(cq.Data, service.ActionApprovePrefix); ok {
+		ref, err = h.actions.Approve(chatID, cq.Message.MessageID, fingerprint)
+		done = "action.approved"
+	} else {
+		ref, err = h.actions.Mute(chatID, cq.Message.MessageID, strings.TrimPrefix(cq.Data, service.ActionMutePrefix))
+		done = "action.muted"
+	}
+
+	switch {
+	case errors.Is(err, service.ErrNoPullRequest):
+		answer("action.no_pr", nil)
+	case errors.Is(err, service.ErrNotReviewRequest):
+		answer("action.not_review_request", nil)
+	case errors.Is(err, service.ErrNoGitHubToken):
+		answer("action.no_token", nil)
+	case errors.Is(err, service.ErrNoGitHubScope):
+		answer("action.no_scope", nil)
+	case err != nil:
+		log.Printf("%s for %d error: %v", cq.Data, chatID, err)
+		answer("action.failed", nil)
+	default:
+		answer(done, i18n.Args{"Repo": ref.Repo, "Number": ref.Number})
+	}
+}
@@ -0,0 +1,37 @@
+package telegram
+
+import (
+	"log"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleCallback проверяет подпись данных inline-кнопки и направляет нажатие обработчику по префиксу.
+func (h *Handler) handleCallback(cq *tgbotapi.CallbackQuery) {
+	if cq.Message == nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+		return
+	}
+	data, ok := h.signer.Verify(cq.Message.Chat.ID, cq.Data)
+	if !ok {
+		log.Printf("callback with bad signature from %d: %q", cq.Message.Chat.ID, cq.Data)
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(cq.Message.Chat.ID, "callback.expired", nil)))
+		return
+	}
+	cq.Data = data
+
+	switch {
+	case strings.HasPrefix(cq.Data, settingsTogglePrefix):
+		h.handleSettingsCallback(cq)
+	case strings.HasPrefix(cq.Data, pendingPagePrefix):
+		h.handlePendingCallback(cq)
+	case strings.HasPrefix(cq.Data, service.ReminderSnoozePrefix):
+		h.handleReminderCallback(cq)
+	case strings.HasPrefix(cq.Data, service.ActionMutePrefix), strings.HasPrefix(cq.Data, service.ActionApprovePrefix):
+		h.handleActionCallback(cq)
+	default:
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+	}
+}
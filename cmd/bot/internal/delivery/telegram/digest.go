@@ -0,0 +1,61 @@
+package telegram
+
+import (
+	"log"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+)
+
+// digestReply обрабатывает /digest с аргументами args и возвращает ответ пользователю.
+func (h *Handler) digestReply(chatID int64, args []string) string {
+	usage := h.t(chatID, "digest.usage", nil)
+	switch {
+	case len(args) == 0:
+		d, err := h.svc.DigestSettings(chatID)
+		if err != nil {
+			log.Printf("get digest settings for %d error: %v", chatID, err)
+			return h.t(chatID, "cmd.load_failed", nil)
+		}
+		if !d.Enabled {
+			return h.t(chatID, "digest.off_state", nil) + "\n" + usage
+		}
+		return h.t(chatID, "digest.on_state", i18n.Args{"Digest": d.String()})
+
+	case len(args) == 1 && (args[0] == "on" || args[0] == "off"):
+		on := args[0] == "on"
+		if err := h.svc.SetDigest(chatID, on); err != nil {
+			return h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+		}
+		if !on {
+			return h.t(chatID, "digest.disabled", nil)
+		}
+		d, err := h.svc.DigestSettings(chatID)
+		if err != nil {
+			return h.t(chatID, "digest.enabled", nil)
+		}
+		return h.t(chatID, "digest.enabled", i18n.Args{"Digest": d.String()})
+
+	case (len(args) == 2 || len(args) == 3) && args[0] == "time":
+		minutes, err := service.ParseClock(args[1])
+		if err != nil {
+			return h.badInput(chatID, err, usage)
+		}
+		if len(args) == 3 {
+			if err := h.svc.SetTimezone(chatID, args[2]); err != nil {
+				return h.badInput(chatID, err, usage)
+			}
+		}
+		if err := h.svc.SetDigestTime(chatID, minutes); err != nil {
+			return h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+		}
+		d, err := h.svc.DigestSettings(chatID)
+		if err != nil {
+			return h.t(chatID, "digest.time_set", i18n.Args{"Digest": args[1]})
+		}
+		return h.t(chatID, "digest.time_set", i18n.Args{"Digest": d.String()})
+
+	default:
+		return usage
+	}
+}
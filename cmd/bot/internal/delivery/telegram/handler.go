@@ -1,38 +1,136 @@
 package telegram
 
 import (
-	"fmt"
+	"errors"
 	"log"
+	"net/http"
 	"strings"
+	"time"
+	"unicode/utf16"
 
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
 	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 type Sender struct {
-	bot *tgbotapi.BotAPI
+	bot    *tgbotapi.BotAPI
+	signer *CallbackSigner
 }
 
-func NewSender(bot *tgbotapi.BotAPI) *Sender {
-	return &Sender{bot: bot}
+func NewSender(bot *tgbotapi.BotAPI, signer *CallbackSigner) *Sender {
+	return &Sender{bot: bot, signer: signer}
 }
 
-func (s *Sender) SendMessage(chatID int64, text string) error {
+func (s *Sender) SendMessage(chatID int64, text string, buttons ...[]service.Button) error {
+	_, err := s.SendMessageID(chatID, text, buttons...)
+	return err
+}
+
+// SendMessageID отправляет сообщение и возвращает его ID в чате.
+func (s *Sender) SendMessageID(chatID int64, text string, buttons ...[]service.Button) (int, error) {
 	msg := tgbotapi.NewMessage(chatID, text)
-	_, err := s.bot.Send(msg)
+	if len(buttons) > 0 {
+		markup := inlineKeyboard(buttons)
+		s.signer.SignMarkup(chatID, &markup)
+		msg.ReplyMarkup = markup
+	}
+	sent, err := s.bot.Send(msg)
+	if err != nil {
+		return 0, wrapSendError(err)
+	}
+	return sent.MessageID, nil
+}
+
+// StrikeMessage зачёркивает text сообщения и дописывает note. Кнопки убираются явно:
+// Approve, «Заглушить» и «Отложить» под устаревшим сообщением уже ни к чему, а ссылка
+// на PR остаётся в тексте.
+func (s *Sender) StrikeMessage(chatID int64, messageID int, text, note string) error {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text+"\n\n"+note)
+	edit.Entities = []tgbotapi.MessageEntity{{Type: "strikethrough", Offset: 0, Length: len(utf16.Encode([]rune(text)))}}
+	edit.ReplyMarkup = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if _, err := s.bot.Request(edit); err != nil {
+		return wrapSendError(err)
+	}
+	return nil
+}
+
+func inlineKeyboard(rows [][]service.Button) tgbotapi.InlineKeyboardMarkup {
+	keyboard := make([][]tgbotapi.InlineKeyboardButton, 0, len(rows))
+	for _, row := range rows {
+		var r []tgbotapi.InlineKeyboardButton
+		for _, b := range row {
+			if b.URL != "" {
+				r = append(r, tgbotapi.NewInlineKeyboardButtonURL(b.Text, b.URL))
+			} else {
+				r = append(r, tgbotapi.NewInlineKeyboardButtonData(b.Text, b.Data))
+			}
+		}
+		keyboard = append(keyboard, r)
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+}
+
+// wrapSendError превращает 429 от Telegram в service.RetryAfterError,
+// а 400 и 403 (чат не найден, бот заблокирован) — в service.PermanentError.
+func wrapSendError(err error) error {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return err
+	}
+	switch {
+	case tgErr.RetryAfter > 0:
+		return &service.RetryAfterError{
+			RetryAfter: time.Duration(tgErr.RetryAfter) * time.Second,
+			Err:        err,
+		}
+	case tgErr.Code == http.StatusBadRequest || tgErr.Code == http.StatusForbidden:
+		return &service.PermanentError{Err: err}
+	}
 	return err
 }
 
 type Handler struct {
-	svc *service.Notifier
-	bot *tgbotapi.BotAPI
+	svc    *service.Notifier
+	linker *service.Linker
+	prs    *service.PullRequestTracker
+	bot    *tgbotapi.BotAPI
+	signer *CallbackSigner
+
+	reminders *service.Reminders
+	replier   *service.Replier
+	actions   *service.PullRequestActions
 }
 
-func NewHandler(svc *service.Notifier, bot *tgbotapi.BotAPI) *Handler {
-	return &Handler{svc: svc, bot: bot}
+// NewHandler создаёт обработчик команд. linker может быть nil, если GitHub OAuth не настроен,
+// reminders — если напоминания выключены, replier и actions — если ответы и кнопки действий не нужны.
+func NewHandler(
+	svc *service.Notifier,
+	linker *service.Linker,
+	prs *service.PullRequestTracker,
+	reminders *service.Reminders,
+	replier *service.Replier,
+	actions *service.PullRequestActions,
+	bot *tgbotapi.BotAPI,
+	signer *CallbackSigner,
+) *Handler {
+	return &Handler{
+		svc:       svc,
+		linker:    linker,
+		prs:       prs,
+		bot:       bot,
+		signer:    signer,
+		reminders: reminders,
+		replier:   replier,
+		actions:   actions,
+	}
 }
 
 func (h *Handler) HandleUpdate(update tgbotapi.Update) {
+	if update.CallbackQuery != nil {
+		h.handleCallback(update.CallbackQuery)
+		return
+	}
 	if update.Message == nil || update.Message.Text == "" {
 		return
 	}
@@ -42,33 +140,100 @@ func (h *Handler) HandleUpdate(update tgbotapi.Update) {
 
 	log.Printf("received message: %s from %d", text, chatID)
 
+	// ответ на уведомление о review comment уходит в тред на GitHub
+	if update.Message.ReplyToMessage != nil && !strings.HasPrefix(text, "/") {
+		h.handleReply(update.Message)
+		return
+	}
+
 	var reply string
 
 	switch {
 	case text == "/start":
-		reply = "Привет! Команды: /setgithub <login>, /me"
+		reply = h.t(chatID, "cmd.start", nil)
 
 	case strings.HasPrefix(text, "/setgithub"):
 		parts := strings.Fields(text)
 		if len(parts) != 2 {
-			reply = "Использование: /setgithub <github_login>"
+			reply = h.t(chatID, "cmd.setgithub_usage", nil)
 		} else {
 			login := parts[1]
 			if err := h.svc.SetGitHubLogin(chatID, login); err != nil {
-				reply = fmt.Sprintf("Ошибка: %v", err)
+				reply = h.t(chatID, "cmd.error", i18n.Args{"Error": err})
 			} else {
-				reply = "Ок, сохранил."
+				reply = h.t(chatID, "cmd.saved", nil)
 			}
 		}
 
+	case text == "/link":
+		if h.linker == nil {
+			reply = h.t(chatID, "cmd.link_disabled", nil)
+			break
+		}
+		dc, err := h.linker.StartLink(chatID)
+		if err != nil {
+			log.Printf("start github link for %d error: %v", chatID, err)
+			reply = h.t(chatID, "cmd.link_failed", nil)
+		} else {
+			reply = h.t(chatID, "cmd.link_code", i18n.Args{
+				"URL":     dc.VerificationURI,
+				"Code":    dc.UserCode,
+				"Minutes": int(dc.ExpiresIn.Minutes()),
+			})
+		}
+
 	case text == "/me":
-		login, err := h.svc.GetMe(chatID)
+		b, err := h.svc.GetMe(chatID)
 		if err != nil {
-			reply = "Пока не задан github login. Используй /setgithub <login>."
+			reply = h.t(chatID, "cmd.no_login", nil)
+		} else if b.Verified {
+			reply = h.t(chatID, "cmd.me_verified", i18n.Args{"Login": b.GitHubLogin})
+		} else {
+			reply = h.t(chatID, "cmd.me_unverified", i18n.Args{"Login": b.GitHubLogin})
+		}
+
+	case strings.HasPrefix(text, "/selfnotify"):
+		parts := strings.Fields(text)
+		if len(parts) != 2 || (parts[1] != "on" && parts[1] != "off") {
+			reply = h.t(chatID, "cmd.selfnotify_usage", nil)
+		} else if err := h.svc.SetSelfNotify(chatID, parts[1] == "on"); err != nil {
+			reply = h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+		} else if parts[1] == "on" {
+			reply = h.t(chatID, "cmd.selfnotify_on", nil)
 		} else {
-			reply = "Твой GitHub login: " + login
+			reply = h.t(chatID, "cmd.selfnotify_off", nil)
 		}
 
+	case text == "/quiet" || strings.HasPrefix(text, "/quiet "):
+		reply = h.quietReply(chatID, strings.Fields(text)[1:])
+
+	case text == "/digest" || strings.HasPrefix(text, "/digest "):
+		reply = h.digestReply(chatID, strings.Fields(text)[1:])
+
+	case text == "/mute" || strings.HasPrefix(text, "/mute "):
+		reply = h.muteReply(chatID, strings.Fields(text)[1:])
+
+	case text == "/unmute" || strings.HasPrefix(text, "/unmute "):
+		reply = h.unmuteReply(chatID, strings.Fields(text)[1:])
+
+	case text == "/mutes":
+		reply = h.mutesReply(chatID)
+
+	case text == "/lang" || strings.HasPrefix(text, "/lang "):
+		reply = h.langReply(chatID, strings.Fields(text)[1:])
+
+	case text == "/pending":
+		h.sendPending(chatID)
+		return
+
+	case text == "/mine":
+		h.sendMine(chatID)
+		return
+
+	case text == "/settings":
+		h.sendSettings(chatID)
+		return
+
 	default:
 		return
 	}
@@ -76,3 +241,28 @@ func (h *Handler) HandleUpdate(update tgbotapi.Update) {
 	msg := tgbotapi.NewMessage(chatID, reply)
 	_, _ = h.bot.Send(msg)
 }
+
+// t — текст key на языке пользователя.
+func (h *Handler) t(chatID int64, key string, args i18n.Args) string {
+	return h.svc.Text(chatID, key, args)
+}
+
+// badInput объясняет на языке пользователя, что не так с аргументом команды.
+func (h *Handler) badInput(chatID int64, err error, usage string) string {
+	var key string
+	switch {
+	case errors.Is(err, service.ErrBadMuteTarget):
+		key = "cmd.bad_mute_target"
+	case errors.Is(err, service.ErrBadMuteDuration):
+		key = "cmd.bad_mute_duration"
+	case errors.Is(err, service.ErrBadClock):
+		key = "cmd.bad_clock"
+	case errors.Is(err, service.ErrBadQuietWindow):
+		key = "cmd.bad_quiet_window"
+	case errors.Is(err, service.ErrBadTimezone):
+		key = "cmd.bad_timezone"
+	default:
+		return h.t(chatID, "cmd.error_usage", i18n.Args{"Error": err, "Usage": usage})
+	}
+	return h.t(chatID, "cmd.error_usage", i18n.Args{"Error": h.t(chatID, key, nil), "Usage": usage})
+}
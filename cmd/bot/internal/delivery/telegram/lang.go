@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"log"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+)
+
+// langReply обрабатывает /lang с аргументами args и возвращает ответ пользователю.
+func (h *Handler) langReply(chatID int64, args []string) string {
+	langs := h.svc.Langs()
+	switch len(args) {
+	case 0:
+		lang, err := h.svc.Lang(chatID)
+		if err != nil {
+			log.Printf("get lang for %d error: %v", chatID, err)
+			return h.t(chatID, "cmd.load_failed", nil)
+		}
+		return h.t(chatID, "cmd.lang_current", i18n.Args{"Lang": lang, "Langs": strings.Join(langs, ", ")})
+
+	case 1:
+		lang := strings.ToLower(args[0])
+		if !containsLang(langs, lang) {
+			return h.t(chatID, "cmd.lang_unsupported", i18n.Args{"Lang": lang, "Langs": strings.Join(langs, ", ")})
+		}
+		if err := h.svc.SetLang(chatID, lang); err != nil {
+			log.Printf("set lang %s for %d error: %v", lang, chatID, err)
+			return h.t(chatID, "cmd.save_failed", nil)
+		}
+		// ответ уже на новом языке
+		return h.t(chatID, "cmd.lang_set", nil)
+
+	default:
+		return h.t(chatID, "cmd.lang_usage", i18n.Args{"Langs": strings.Join(langs, "|")})
+	}
+}
+
+func containsLang(langs []string, lang string) bool {
+	for _, l := range langs {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const mineLimit = 20
+
+// ключи i18n для состояний review
+var reviewStateLabels = map[string]string{
+	"approved":            "mine.approved",
+	"changes_requested":   "mine.changes_requested",
+	"commented":           "mine.commented",
+	service.ReviewPending: "mine.pending",
+}
+
+func (h *Handler) sendMine(chatID int64) {
+	msg := tgbotapi.NewMessage(chatID, h.mineText(chatID))
+	msg.DisableWebPagePreview = true
+	_, _ = h.bot.Send(msg)
+}
+
+func (h *Handler) mineText(chatID int64) string {
+	if _, err := h.svc.GetMe(chatID); err != nil {
+		return h.t(chatID, "cmd.no_login", nil)
+	}
+
+	prs, err := h.prs.MyPullRequests(chatID)
+	if err != nil {
+		log.Printf("my pull requests for %d error: %v", chatID, err)
+		return h.t(chatID, "cmd.list_failed", nil)
+	}
+	return formatMine(prs, func(key string, args i18n.Args) string { return h.t(chatID, key, args) })
+}
+
+// formatMine собирает /mine; text отдаёт тексты на языке пользователя.
+func formatMine(prs []service.AuthoredPullRequest, text func(key string, args i18n.Args) string) string {
+	if len(prs) == 0 {
+		return text("mine.empty", nil)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(text("mine.header", i18n.Args{"Count": len(prs)}))
+	for _, pr := range prs[:min(len(prs), mineLimit)] {
+		fmt.Fprintf(&sb, "\n\n%s#%d %s", pr.Ref.Repo, pr.Ref.Number, pr.Ref.Title)
+		if pr.Draft {
+			sb.WriteString(text("mine.draft", nil))
+		}
+		sb.WriteString("\n")
+		sb.WriteString(pr.Ref.URL)
+
+		if len(pr.Reviewers) == 0 {
+			sb.WriteString("\n" + text("mine.no_reviewers", nil))
+		}
+		for _, rv := range pr.Reviewers {
+			sb.WriteString("\n")
+			if key, ok := reviewStateLabels[rv.State]; ok {
+				sb.WriteString(text(key, i18n.Args{"Login": rv.Login}))
+			} else {
+				sb.WriteString(rv.Login + " — " + rv.State)
+			}
+		}
+
+		sb.WriteString("\n")
+		switch {
+		case pr.Mergeable == nil:
+			sb.WriteString(text("mine.merge_unknown", nil))
+		case *pr.Mergeable:
+			sb.WriteString(text("mine.merge_clean", nil))
+		default:
+			sb.WriteString(text("mine.merge_conflict", nil))
+		}
+	}
+	if len(prs) > mineLimit {
+		sb.WriteString("\n\n" + text("notify.more", i18n.Args{"Count": len(prs) - mineLimit}))
+	}
+	return sb.String()
+}
@@ -0,0 +1,89 @@
+package telegram
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+)
+
+// muteReply обрабатывает /mute с аргументами args и возвращает ответ пользователю.
+func (h *Handler) muteReply(chatID int64, args []string) string {
+	usage := h.t(chatID, "mute.usage", nil)
+	if len(args) == 0 || len(args) > 2 {
+		return usage
+	}
+	target, err := service.ParseMuteTarget(args[0])
+	if err != nil {
+		return h.badInput(chatID, err, usage)
+	}
+	var d time.Duration
+	if len(args) == 2 {
+		if d, err = service.ParseMuteDuration(args[1]); err != nil {
+			return h.badInput(chatID, err, usage)
+		}
+	}
+
+	m, err := h.svc.Mute(chatID, target, d)
+	if err != nil {
+		log.Printf("mute %s for %d error: %v", target, chatID, err)
+		return h.t(chatID, "cmd.save_failed", nil)
+	}
+	if m.ExpiresAt.IsZero() {
+		return h.t(chatID, "mute.done", i18n.Args{"Target": target})
+	}
+	return h.t(chatID, "mute.done", i18n.Args{"Target": target, "Until": h.formatUntil(chatID, m.ExpiresAt)})
+}
+
+func (h *Handler) unmuteReply(chatID int64, args []string) string {
+	usage := h.t(chatID, "unmute.usage", nil)
+	if len(args) != 1 {
+		return usage
+	}
+	target, err := service.ParseMuteTarget(args[0])
+	if err != nil {
+		return h.badInput(chatID, err, usage)
+	}
+	ok, err := h.svc.Unmute(chatID, target)
+	if err != nil {
+		log.Printf("unmute %s for %d error: %v", target, chatID, err)
+		return h.t(chatID, "cmd.save_failed", nil)
+	}
+	if !ok {
+		return h.t(chatID, "unmute.not_muted", i18n.Args{"Target": target})
+	}
+	return h.t(chatID, "unmute.done", i18n.Args{"Target": target})
+}
+
+func (h *Handler) mutesReply(chatID int64) string {
+	mutes, err := h.svc.Mutes(chatID)
+	if err != nil {
+		log.Printf("list mutes for %d error: %v", chatID, err)
+		return h.t(chatID, "cmd.list_failed", nil)
+	}
+	if len(mutes) == 0 {
+		return h.t(chatID, "mutes.empty", nil) + "\n" + h.t(chatID, "mute.usage", nil)
+	}
+
+	var b strings.Builder
+	b.WriteString(h.t(chatID, "mutes.header", nil))
+	for _, m := range mutes {
+		b.WriteString("\n• " + m.Target)
+		if !m.ExpiresAt.IsZero() {
+			b.WriteString(h.t(chatID, "mutes.until", i18n.Args{"Until": h.formatUntil(chatID, m.ExpiresAt)}))
+		}
+	}
+	return b.String()
+}
+
+// formatUntil показывает момент в часовом поясе пользователя.
+func (h *Handler) formatUntil(chatID int64, t time.Time) string {
+	if tz, err := h.svc.Timezone(chatID); err == nil && tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			t = t.In(loc)
+		}
+	}
+	return t.Format("02.01 15:04")
+}
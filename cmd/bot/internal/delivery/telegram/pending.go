@@ -0,0 +1,101 @@
+package telegram
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	pendingPagePrefix = "pending:page:"
+	pendingPageSize   = 5
+)
+
+func (h *Handler) sendPending(chatID int64) {
+	text, markup := h.pendingPage(chatID, 0)
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.DisableWebPagePreview = true
+	if markup != nil {
+		msg.ReplyMarkup = *markup
+	}
+	_, _ = h.bot.Send(msg)
+}
+
+func (h *Handler) handlePendingCallback(cq *tgbotapi.CallbackQuery) {
+	page, err := strconv.Atoi(strings.TrimPrefix(cq.Data, pendingPagePrefix))
+	if err != nil || page < 0 {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+		return
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+
+	chatID := cq.Message.Chat.ID
+	text, markup := h.pendingPage(chatID, page)
+	edit := tgbotapi.NewEditMessageText(chatID, cq.Message.MessageID, text)
+	edit.DisableWebPagePreview = true
+	edit.ReplyMarkup = markup
+	_, _ = h.bot.Request(edit)
+}
+
+// pendingPage строит страницу page списка /pending и кнопки перехода между страницами.
+func (h *Handler) pendingPage(chatID int64, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if _, err := h.svc.GetMe(chatID); err != nil {
+		return h.t(chatID, "cmd.no_login", nil), nil
+	}
+
+	items, err := h.prs.PendingReviews(chatID)
+	if err != nil {
+		log.Printf("pending reviews for %d error: %v", chatID, err)
+		return h.t(chatID, "cmd.list_failed", nil), nil
+	}
+	t := func(key string, args i18n.Args) string { return h.t(chatID, key, args) }
+	text, markup := formatPending(items, page, time.Now(), t)
+	h.signer.SignMarkup(chatID, markup)
+	return text, markup
+}
+
+// formatPending собирает страницу /pending; text отдаёт тексты на языке пользователя.
+func formatPending(items []service.ReviewRequest, page int, now time.Time, text func(key string, args i18n.Args) string) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if len(items) == 0 {
+		return text("pending.empty", nil), nil
+	}
+
+	pages := (len(items) + pendingPageSize - 1) / pendingPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+	from := page * pendingPageSize
+	to := min(from+pendingPageSize, len(items))
+
+	var sb strings.Builder
+	sb.WriteString(text("pending.header", i18n.Args{"Count": len(items), "Page": page + 1, "Pages": pages}))
+	for i, it := range items[from:to] {
+		age := now.Sub(it.RequestedAt)
+		fmt.Fprintf(&sb, "\n\n%d. %s#%d %s\n", from+i+1, it.Ref.Repo, it.Ref.Number, it.Ref.Title)
+		sb.WriteString(text("pending.item", i18n.Args{
+			"Hours":  int(age.Hours()),
+			"Days":   int(age.Hours() / 24),
+			"Author": it.Author,
+		}))
+		sb.WriteString("\n" + it.Ref.URL)
+	}
+
+	if pages == 1 {
+		return sb.String(), nil
+	}
+	var row []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text("button.prev", nil), pendingPagePrefix+strconv.Itoa(page-1)))
+	}
+	if page < pages-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text("button.next", nil), pendingPagePrefix+strconv.Itoa(page+1)))
+	}
+	markup := tgbotapi.NewInlineKeyboardMarkup(row)
+	return sb.String(), &markup
+}
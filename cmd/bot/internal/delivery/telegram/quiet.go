@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"log"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+)
+
+// quietReply обрабатывает /quiet с аргументами args и возвращает ответ пользователю.
+func (h *Handler) quietReply(chatID int64, args []string) string {
+	usage := h.t(chatID, "quiet.usage", nil)
+	switch {
+	case len(args) == 0:
+		q, err := h.svc.QuietHours(chatID)
+		if err != nil {
+			log.Printf("get quiet hours for %d error: %v", chatID, err)
+			return h.t(chatID, "cmd.load_failed", nil)
+		}
+		if q == nil {
+			return h.t(chatID, "quiet.off_state", nil) + "\n" + usage
+		}
+		return h.t(chatID, "quiet.state", i18n.Args{"Quiet": q.String()})
+
+	case len(args) == 1 && args[0] == "off":
+		if err := h.svc.SetQuietHours(chatID, nil); err != nil {
+			return h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+		}
+		return h.t(chatID, "quiet.disabled", nil)
+
+	case len(args) <= 2:
+		var tz string
+		if len(args) == 2 {
+			tz = args[1]
+		} else {
+			cur, err := h.svc.Timezone(chatID)
+			if err != nil {
+				return h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+			}
+			tz = cur
+		}
+
+		q, err := service.ParseQuietHours(args[0], tz)
+		if err != nil {
+			return h.badInput(chatID, err, usage)
+		}
+		if err := h.svc.SetQuietHours(chatID, &q); err != nil {
+			return h.t(chatID, "cmd.error", i18n.Args{"Error": err})
+		}
+		return h.t(chatID, "quiet.set", i18n.Args{"Quiet": q.String()})
+
+	default:
+		return usage
+	}
+}
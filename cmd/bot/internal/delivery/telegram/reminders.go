@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleReminderCallback(cq *tgbotapi.CallbackQuery) {
+	id, err := strconv.ParseInt(strings.TrimPrefix(cq.Data, service.ReminderSnoozePrefix), 10, 64)
+	if err != nil || h.reminders == nil {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, ""))
+		return
+	}
+
+	chatID := cq.Message.Chat.ID
+	until, err := h.reminders.Snooze(id, time.Now())
+	if err != nil {
+		log.Printf("snooze reminder %d for %d error: %v", id, chatID, err)
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, "reminder.snooze_failed", nil)))
+		return
+	}
+
+	if tz, err := h.svc.Timezone(chatID); err == nil && tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			until = until.In(loc)
+		}
+	}
+	_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, "reminder.snoozed", i18n.Args{"Time": until.Format("15:04")})))
+
+	// кнопка больше не нужна
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, cq.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	})
+	_, _ = h.bot.Request(edit)
+}
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"errors"
+	"log"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func (h *Handler) handleReply(m *tgbotapi.Message) {
+	chatID := m.Chat.ID
+	reply := func(key string, args i18n.Args) {
+		msg := tgbotapi.NewMessage(chatID, h.t(chatID, key, args))
+		msg.ReplyToMessageID = m.MessageID
+		_, _ = h.bot.Send(msg)
+	}
+
+	if h.replier == nil {
+		reply("reply.disabled", nil)
+		return
+	}
+
+	ref, err := h.replier.Reply(chatID, m.ReplyToMessage.MessageID, m.Text)
+	switch {
+	case errors.Is(err, service.ErrNotReplyable):
+		reply("reply.not_replyable", nil)
+	case errors.Is(err, service.ErrNoGitHubToken):
+		reply("reply.no_token", nil)
+	case errors.Is(err, service.ErrNoGitHubScope):
+		reply("reply.no_scope", nil)
+	case err != nil:
+		log.Printf("reply to review comment for %d error: %v", chatID, err)
+		reply("reply.failed", nil)
+	default:
+		reply("reply.done", i18n.Args{"Repo": ref.Repo, "Number": ref.Number})
+	}
+}
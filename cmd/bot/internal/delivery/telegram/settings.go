@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"log"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const settingsTogglePrefix = "settings:toggle:"
+
+// eventLabel — название типа событий на языке пользователя.
+func (h *Handler) eventLabel(chatID int64, kind service.EventKind) string {
+	return h.t(chatID, "event."+string(kind), nil)
+}
+
+func (h *Handler) sendSettings(chatID int64) {
+	prefs, err := h.svc.Preferences(chatID)
+	if err != nil {
+		log.Printf("get preferences for %d error: %v", chatID, err)
+		_, _ = h.bot.Send(tgbotapi.NewMessage(chatID, h.t(chatID, "cmd.load_failed", nil)))
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, h.t(chatID, "settings.title", nil))
+	markup := h.settingsKeyboard(chatID, prefs)
+	h.signer.SignMarkup(chatID, &markup)
+	msg.ReplyMarkup = markup
+	_, _ = h.bot.Send(msg)
+}
+
+func (h *Handler) handleSettingsCallback(cq *tgbotapi.CallbackQuery) {
+	chatID := cq.Message.Chat.ID
+	kind, ok := service.ParseEventKind(strings.TrimPrefix(cq.Data, settingsTogglePrefix))
+	if !ok {
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, "settings.unknown", nil)))
+		return
+	}
+
+	enabled, err := h.svc.ToggleEvent(chatID, kind)
+	if err != nil {
+		log.Printf("toggle %s for %d error: %v", kind, chatID, err)
+		_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, h.t(chatID, "settings.failed", nil)))
+		return
+	}
+
+	answer := h.t(chatID, "settings.toggled", i18n.Args{"Label": h.eventLabel(chatID, kind), "Enabled": enabled})
+	_, _ = h.bot.Request(tgbotapi.NewCallback(cq.ID, answer))
+
+	prefs, err := h.svc.Preferences(chatID)
+	if err != nil {
+		log.Printf("get preferences for %d error: %v", chatID, err)
+		return
+	}
+	markup := h.settingsKeyboard(chatID, prefs)
+	h.signer.SignMarkup(chatID, &markup)
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, cq.Message.MessageID, markup)
+	_, _ = h.bot.Request(edit)
+}
+
+func (h *Handler) settingsKeyboard(chatID int64, prefs map[service.EventKind]bool) tgbotapi.InlineKeyboardMarkup {
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(service.EventKinds))
+	for _, k := range service.EventKinds {
+		mark := "❌"
+		if prefs[k] {
+			mark = "✅"
+		}
+		btn := tgbotapi.NewInlineKeyboardButtonData(mark+" "+h.eventLabel(chatID, k), settingsTogglePrefix+string(k))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(btn))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
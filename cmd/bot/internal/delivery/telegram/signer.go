@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// signatureLen — сколько байт HMAC хранить в кнопке: callback_data в Telegram не длиннее 64 байт.
+const signatureLen = 9
+
+// CallbackSigner подписывает callback-данные кнопок вместе с ID чата,
+// так что подделанные данные или кнопка из чужого чата не пройдут проверку.
+type CallbackSigner struct {
+	key []byte
+}
+
+func NewCallbackSigner(secret string) *CallbackSigner {
+	return &CallbackSigner{key: []byte(secret)}
+}
+
+// Sign возвращает data с подписью через "|".
+func (s *CallbackSigner) Sign(chatID int64, data string) string {
+	return data + "|" + s.signature(chatID, data)
+}
+
+// Verify проверяет подпись и возвращает исходные данные.
+func (s *CallbackSigner) Verify(chatID int64, signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '|')
+	if i < 0 {
+		return "", false
+	}
+	data, sig := signed[:i], signed[i+1:]
+	if !hmac.Equal([]byte(sig), []byte(s.signature(chatID, data))) {
+		return "", false
+	}
+	return data, true
+}
+
+// SignMarkup подписывает все callback-кнопки клавиатуры.
+func (s *CallbackSigner) SignMarkup(chatID int64, markup *tgbotapi.InlineKeyboardMarkup) {
+	if markup == nil {
+		return
+	}
+	for _, row := range markup.InlineKeyboard {
+		for i := range row {
+			if row[i].CallbackData != nil {
+				signed := s.Sign(chatID, *row[i].CallbackData)
+				row[i].CallbackData = &signed
+			}
+		}
+	}
+}
+
+func (s *CallbackSigner) signature(chatID int64, data string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strconv.FormatInt(chatID, 10)))
+	mac.Write([]byte{0})
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLen])
+}
@@ -0,0 +1,253 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOAuthURL = "https://github.com"
+	DefaultAPIURL   = "https://api.github.com"
+)
+
+// Ошибки device flow, которые GitHub возвращает при опросе токена.
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("slow down")
+	ErrExpiredToken         = errors.New("device code expired")
+	ErrAccessDenied         = errors.New("access denied by user")
+)
+
+// ErrInsufficientScope — у токена нет scope repo (или public_repo), а запрос пишет в репозиторий.
+var ErrInsufficientScope = errors.New("token lacks repo scope")
+
+type Config struct {
+	ClientID string
+	OAuthURL string // адрес login/device/code и login/oauth/access_token
+	APIURL   string // адрес REST API
+}
+
+type Client struct {
+	clientID string
+	oauthURL string
+	apiURL   string
+	http     *http.Client
+}
+
+func NewClient(cfg Config, httpClient *http.Client) *Client {
+	if cfg.OAuthURL == "" {
+		cfg.OAuthURL = DefaultOAuthURL
+	}
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultAPIURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Client{
+		clientID: cfg.ClientID,
+		oauthURL: strings.TrimRight(cfg.OAuthURL, "/"),
+		apiURL:   strings.TrimRight(cfg.APIURL, "/"),
+		http:     httpClient,
+	}
+}
+
+type DeviceCode struct {
+	DeviceCode      string
+	UserCode        string
+	VerificationURI string
+	ExpiresIn       time.Duration
+	Interval        time.Duration
+}
+
+type User struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+}
+
+func (c *Client) RequestDeviceCode(ctx context.Context, scope string) (DeviceCode, error) {
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("scope", scope)
+
+	var resp struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+		ExpiresIn       int    `json:"expires_in"`
+		Interval        int    `json:"interval"`
+		Error           string `json:"error"`
+		ErrorDesc       string `json:"error_description"`
+	}
+	if err := c.postForm(ctx, c.oauthURL+"/login/device/code", form, &resp); err != nil {
+		return DeviceCode{}, fmt.Errorf("request device code: %w", err)
+	}
+	if resp.Error != "" {
+		return DeviceCode{}, fmt.Errorf("request device code: %s: %s", resp.Error, resp.ErrorDesc)
+	}
+
+	return DeviceCode{
+		DeviceCode:      resp.DeviceCode,
+		UserCode:        resp.UserCode,
+		VerificationURI: resp.VerificationURI,
+		ExpiresIn:       time.Duration(resp.ExpiresIn) * time.Second,
+		Interval:        time.Duration(resp.Interval) * time.Second,
+	}, nil
+}
+
+// PollAccessToken делает одну попытку обменять device code на токен.
+// Пока пользователь не подтвердил вход, возвращает ErrAuthorizationPending.
+func (c *Client) PollAccessToken(ctx context.Context, deviceCode string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("device_code", deviceCode)
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+		ErrorDesc   string `json:"error_description"`
+	}
+	if err := c.postForm(ctx, c.oauthURL+"/login/oauth/access_token", form, &resp); err != nil {
+		return "", fmt.Errorf("poll access token: %w", err)
+	}
+
+	switch resp.Error {
+	case "":
+		if resp.AccessToken == "" {
+			return "", errors.New("poll access token: empty token")
+		}
+		return resp.AccessToken, nil
+	case "authorization_pending":
+		return "", ErrAuthorizationPending
+	case "slow_down":
+		return "", ErrSlowDown
+	case "expired_token":
+		return "", ErrExpiredToken
+	case "access_denied":
+		return "", ErrAccessDenied
+	default:
+		return "", fmt.Errorf("poll access token: %s: %s", resp.Error, resp.ErrorDesc)
+	}
+}
+
+// GetUser возвращает владельца токена.
+func (c *Client) GetUser(ctx context.Context, token string) (User, error) {
+	var u User
+	if err := c.doAPI(ctx, http.MethodGet, "/user", token, nil, &u); err != nil {
+		return User{}, fmt.Errorf("get user: %w", err)
+	}
+	return u, nil
+}
+
+// ReplyToReviewComment отвечает в треде review comment commentID в PR number репозитория repo (owner/name).
+func (c *Client) ReplyToReviewComment(ctx context.Context, token, repo string, number int, commentID int64, body string) error {
+	payload, err := json.Marshal(struct {
+		Body string `json:"body"`
+	}{Body: body})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repos/%s/pulls/%d/comments/%d/replies", repo, number, commentID)
+	if err := c.doAPI(ctx, http.MethodPost, path, token, bytes.NewReader(payload), nil); err != nil {
+		return fmt.Errorf("reply to review comment: %w", err)
+	}
+	return nil
+}
+
+// ApproveReview одобряет PR number репозитория repo от имени владельца токена.
+func (c *Client) ApproveReview(ctx context.Context, token, repo string, number int) error {
+	payload, err := json.Marshal(struct {
+		Event string `json:"event"`
+	}{Event: "APPROVE"})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, number)
+	if err := c.doAPI(ctx, http.MethodPost, path, token, bytes.NewReader(payload), nil); err != nil {
+		return fmt.Errorf("approve pull request: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) postForm(ctx context.Context, endpoint string, form url.Values, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	return c.do(req, out)
+}
+
+func (c *Client) doAPI(ctx context.Context, method, path, token string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.do(req, out)
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		if missingScope(resp) {
+			return fmt.Errorf("unexpected status %d: %w", resp.StatusCode, ErrInsufficientScope)
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// missingScope сообщает, что GitHub отказал токену без scope на репозитории. Для чужих
+// приватных репозиториев GitHub отвечает 404, поэтому он тоже считается. X-OAuth-Scopes
+// есть только у OAuth-токенов; без него причину отказа не узнать.
+func missingScope(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	granted, ok := resp.Header["X-Oauth-Scopes"]
+	if !ok {
+		return false
+	}
+	for _, scope := range strings.Split(strings.Join(granted, ","), ",") {
+		switch strings.TrimSpace(scope) {
+		case "repo", "public_repo":
+			return false
+		}
+	}
+	return true
+}
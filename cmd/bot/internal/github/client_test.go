@@ -0,0 +1,159 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newFakeGitHub(t *testing.T, pendingPolls int) *httptest.Server {
+	t.Helper()
+
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/device/code", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("client_id") != "client" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"device_code":"dev","user_code":"ABCD-1234","verification_uri":"https://github.com/login/device","expires_in":900,"interval":5}`))
+	})
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("device_code") != "dev" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		polls++
+		if polls <= pendingPolls {
+			_, _ = w.Write([]byte(`{"error":"authorization_pending"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"tok","token_type":"bearer"}`))
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":42,"login":"Octocat"}`))
+	})
+
+	mux.HandleFunc("POST /repos/org/repo/pulls/5/comments/77/replies", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Body == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":78}`))
+	})
+
+	mux.HandleFunc("POST /repos/org/repo/pulls/5/reviews", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer narrow" {
+			w.Header().Set("X-OAuth-Scopes", "read:user")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Event string `json:"event"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Event != "APPROVE" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":90,"state":"APPROVED"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_DeviceFlow(t *testing.T) {
+	srv := newFakeGitHub(t, 1)
+	c := NewClient(Config{ClientID: "client", OAuthURL: srv.URL, APIURL: srv.URL}, nil)
+	ctx := context.Background()
+
+	dc, err := c.RequestDeviceCode(ctx, "read:user")
+	if err != nil {
+		t.Fatalf("RequestDeviceCode: %v", err)
+	}
+	if dc.UserCode != "ABCD-1234" || dc.Interval != 5*time.Second || dc.ExpiresIn != 15*time.Minute {
+		t.Fatalf("unexpected device code: %+v", dc)
+	}
+
+	if _, err := c.PollAccessToken(ctx, dc.DeviceCode); !errors.Is(err, ErrAuthorizationPending) {
+		t.Fatalf("expected ErrAuthorizationPending, got %v", err)
+	}
+
+	token, err := c.PollAccessToken(ctx, dc.DeviceCode)
+	if err != nil {
+		t.Fatalf("PollAccessToken: %v", err)
+	}
+	if token != "tok" {
+		t.Fatalf("expected token tok, got %q", token)
+	}
+
+	u, err := c.GetUser(ctx, token)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if u.ID != 42 || u.Login != "Octocat" {
+		t.Fatalf("unexpected user: %+v", u)
+	}
+}
+
+func TestClient_GetUser_Unauthorized(t *testing.T) {
+	srv := newFakeGitHub(t, 0)
+	c := NewClient(Config{ClientID: "client", OAuthURL: srv.URL, APIURL: srv.URL}, nil)
+
+	if _, err := c.GetUser(context.Background(), "bad"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestClient_ReplyToReviewComment(t *testing.T) {
+	srv := newFakeGitHub(t, 0)
+	c := NewClient(Config{APIURL: srv.URL}, nil)
+	ctx := context.Background()
+
+	if err := c.ReplyToReviewComment(ctx, "tok", "org/repo", 5, 77, "fixed"); err != nil {
+		t.Fatalf("ReplyToReviewComment: %v", err)
+	}
+	if err := c.ReplyToReviewComment(ctx, "bad", "org/repo", 5, 77, "fixed"); err == nil {
+		t.Fatalf("expected error for bad token")
+	}
+}
+
+func TestClient_ApproveReview(t *testing.T) {
+	srv := newFakeGitHub(t, 0)
+	c := NewClient(Config{APIURL: srv.URL}, nil)
+	ctx := context.Background()
+
+	if err := c.ApproveReview(ctx, "tok", "org/repo", 5); err != nil {
+		t.Fatalf("ApproveReview: %v", err)
+	}
+	if err := c.ApproveReview(ctx, "bad", "org/repo", 5); err == nil || errors.Is(err, ErrInsufficientScope) {
+		t.Fatalf("expected plain error for bad token, got %v", err)
+	}
+	if err := c.ApproveReview(ctx, "narrow", "org/repo", 5); !errors.Is(err, ErrInsufficientScope) {
+		t.Fatalf("expected ErrInsufficientScope for read:user token, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+// Package i18n хранит тексты бота в виде шаблонов text/template по языкам.
+// Встроенные locales/<lang>.yml можно переопределить файлами с теми же именами из каталога.
+package i18n
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultLang — язык встроенных текстов по умолчанию.
+const DefaultLang = "ru"
+
+//go:embed locales/*.yml
+var embedded embed.FS
+
+// Args — параметры шаблона.
+type Args map[string]any
+
+// Catalog — шаблоны текстов по языкам. Ключа нет в языке пользователя — берётся язык по умолчанию.
+type Catalog struct {
+	fallback string
+	langs    map[string]map[string]*template.Template
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultCatalog *Catalog
+)
+
+// Default — каталог только из встроенных текстов.
+func Default() *Catalog {
+	defaultOnce.Do(func() {
+		c, err := Load("", DefaultLang)
+		if err != nil {
+			panic(fmt.Sprintf("i18n: embedded locales: %v", err))
+		}
+		defaultCatalog = c
+	})
+	return defaultCatalog
+}
+
+// Load читает встроенные тексты и поверх них файлы <lang>.yml из dir (пустой dir — без переопределений).
+// Ключи, которых нет во встроенных текстах, считаются опечаткой.
+func Load(dir, fallback string) (*Catalog, error) {
+	raw, err := readLocales(embedded, "locales")
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool)
+	for _, texts := range raw {
+		for key := range texts {
+			known[key] = true
+		}
+	}
+
+	if dir != "" {
+		overrides, err := readLocales(os.DirFS(dir), ".")
+		if err != nil {
+			return nil, err
+		}
+		for lang, texts := range overrides {
+			if raw[lang] == nil {
+				raw[lang] = make(map[string]string, len(texts))
+			}
+			for key, text := range texts {
+				if !known[key] {
+					return nil, fmt.Errorf("%s: unknown key %q", filepath.Join(dir, lang+".yml"), key)
+				}
+				raw[lang][key] = text
+			}
+		}
+	}
+
+	if fallback == "" {
+		fallback = DefaultLang
+	}
+	if raw[fallback] == nil {
+		return nil, fmt.Errorf("no texts for default language %q", fallback)
+	}
+
+	c := &Catalog{fallback: fallback, langs: make(map[string]map[string]*template.Template, len(raw))}
+	for lang, texts := range raw {
+		c.langs[lang] = make(map[string]*template.Template, len(texts))
+		for key, text := range texts {
+			tmpl, err := template.New(key).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", lang, key, err)
+			}
+			c.langs[lang][key] = tmpl
+		}
+	}
+	return c, nil
+}
+
+func readLocales(fsys fs.FS, dir string) (map[string]map[string]string, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.yml"))
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]map[string]string, len(files))
+	for _, name := range files {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		var texts map[string]string
+		if err := yaml.Unmarshal(data, &texts); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", name, err)
+		}
+		out[strings.TrimSuffix(path.Base(name), ".yml")] = texts
+	}
+	return out, nil
+}
+
+// Supported сообщает, есть ли тексты на языке lang.
+func (c *Catalog) Supported(lang string) bool {
+	_, ok := c.langs[lang]
+	return ok
+}
+
+// Langs — доступные языки по алфавиту.
+func (c *Catalog) Langs() []string {
+	out := make([]string, 0, len(c.langs))
+	for lang := range c.langs {
+		out = append(out, lang)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// DefaultLang — язык, на котором говорят с пользователями, не выбравшими свой.
+func (c *Catalog) DefaultLang() string {
+	return c.fallback
+}
+
+// Text подставляет args в шаблон key на языке lang. Неизвестный ключ возвращается как есть.
+func (c *Catalog) Text(lang, key string, args Args) string {
+	tmpl, ok := c.langs[lang][key]
+	if !ok {
+		tmpl, ok = c.langs[c.fallback][key]
+	}
+	if !ok {
+		log.Printf("[i18n] unknown text %q", key)
+		return key
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, args); err != nil {
+		log.Printf("[i18n] render %s/%s: %v", lang, key, err)
+		return key
+	}
+	return buf.String()
+}
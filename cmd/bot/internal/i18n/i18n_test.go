@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefault_LocalesHaveSameKeys(t *testing.T) {
+	c := Default()
+	if got := strings.Join(c.Langs(), ","); got != "en,ru" {
+		t.Fatalf("unexpected langs %s", got)
+	}
+	for key := range c.langs["ru"] {
+		if _, ok := c.langs["en"][key]; !ok {
+			t.Errorf("en has no %q", key)
+		}
+	}
+	for key := range c.langs["en"] {
+		if _, ok := c.langs["ru"][key]; !ok {
+			t.Errorf("ru has no %q", key)
+		}
+	}
+}
+
+func TestCatalog_Text(t *testing.T) {
+	c := Default()
+	args := Args{"Title": "Fix", "URL": "https://x/1", "Review": "lgtm", "Own": true}
+
+	if got := c.Text("en", "notify.approved", args); got != "Your PR was approved: Fix — https://x/1\n\nReview: lgtm" {
+		t.Fatalf("unexpected en text %q", got)
+	}
+	delete(args, "Review")
+	delete(args, "Own")
+	if got := c.Text("ru", "notify.approved", args); got != "PR одобрен: Fix — https://x/1" {
+		t.Fatalf("unexpected ru text %q", got)
+	}
+	if got := c.Text("de", "note.merged", nil); got != "✅ PR влит" {
+		t.Fatalf("expected fallback to ru, got %q", got)
+	}
+	if got := c.Text("ru", "no.such.key", nil); got != "no.such.key" {
+		t.Fatalf("expected key for unknown text, got %q", got)
+	}
+}
+
+func TestLoad_Overrides(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	write("en.yml", `note.merged: "Merged 🎉"`)
+	write("de.yml", `note.merged: "PR gemergt"`)
+
+	c, err := Load(dir, "en")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := c.Text("en", "note.merged", nil); got != "Merged 🎉" {
+		t.Fatalf("unexpected override %q", got)
+	}
+	if got := c.Text("de", "note.merged", nil); got != "PR gemergt" {
+		t.Fatalf("unexpected new language text %q", got)
+	}
+	// чего нет в de, берётся из языка по умолчанию
+	if got := c.Text("de", "note.closed", nil); got != "🚫 PR closed without merging" {
+		t.Fatalf("unexpected fallback %q", got)
+	}
+
+	write("en.yml", `note.mergd: "typo"`)
+	if _, err := Load(dir, "en"); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+	write("en.yml", `note.merged: "{{.Broken"`)
+	if _, err := Load(dir, "en"); err == nil {
+		t.Fatalf("expected error for bad template")
+	}
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "time"
+
+// DeliveryRepository хранит GUID уже обработанных доставок GitHub webhook (X-GitHub-Delivery).
+type DeliveryRepository interface {
+	// MarkDelivery запоминает доставку до now+ttl. Возвращает false, если она уже была
+	// и срок её хранения ещё не истёк.
+	MarkDelivery(id string, now time.Time, ttl time.Duration) (bool, error)
+	// ForgetDelivery удаляет отметку, если доставку не удалось принять в обработку.
+	ForgetDelivery(id string) error
+	DeleteExpiredDeliveries(now time.Time) error
+}
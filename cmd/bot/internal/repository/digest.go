@@ -0,0 +1,29 @@
+package repository
+
+import "time"
+
+// DigestEvent — событие, отложенное до ежедневного дайджеста пользователя.
+type DigestEvent struct {
+	ID         int64
+	TelegramID int64
+	Kind       string
+	Repo       string
+	Number     int
+	Title      string
+	URL        string
+	Actor      string
+	Text       string
+	CreatedAt  time.Time
+}
+
+type DigestRepository interface {
+	AddDigestEvent(e DigestEvent) error
+	// DigestRecipients возвращает пользователей, у которых есть непоказанные события.
+	DigestRecipients() ([]int64, error)
+	// PendingDigestEvents возвращает события пользователя, созданные до before, по порядку.
+	PendingDigestEvents(tgID int64, before time.Time) ([]DigestEvent, error)
+	DeleteDigestEvents(ids []int64) error
+	// LastDigestSlot возвращает слот последнего разосланного дайджеста; нулевое время — ещё не было.
+	LastDigestSlot(tgID int64) (time.Time, error)
+	MarkDigestSent(tgID int64, slot time.Time) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import "time"
+
+// HeldNotification — уведомление, отложенное до конца тихих часов получателя.
+type HeldNotification struct {
+	ID         int64
+	TelegramID int64
+	Text       string
+	CreatedAt  time.Time
+	ReleaseAt  time.Time
+}
+
+type HeldRepository interface {
+	Hold(n HeldNotification) error
+	// DueHeld возвращает до limit уведомлений, которым пора уйти, в порядке поступления.
+	DueHeld(now time.Time, limit int) ([]HeldNotification, error)
+	DeleteHeld(ids []int64) error
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type CredentialsRepo struct {
+	mu   sync.RWMutex
+	byTG map[int64]repository.GitHubCredentials
+}
+
+func NewCredentialsRepo() *CredentialsRepo {
+	return &CredentialsRepo{byTG: make(map[int64]repository.GitHubCredentials)}
+}
+
+func (r *CredentialsRepo) SaveCredentials(c repository.GitHubCredentials) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.GitHubLogin = normalizeLogin(c.GitHubLogin)
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	r.byTG[c.TelegramID] = c
+	return nil
+}
+
+func (r *CredentialsRepo) GetCredentials(tgID int64) (*repository.GitHubCredentials, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c, ok := r.byTG[tgID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (r *CredentialsRepo) DeleteCredentials(tgID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byTG, tgID)
+	return nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"sync"
+	"time"
+)
+
+type DeliveryRepo struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func NewDeliveryRepo() *DeliveryRepo {
+	return &DeliveryRepo{expires: make(map[string]time.Time)}
+}
+
+func (r *DeliveryRepo) MarkDelivery(id string, now time.Time, ttl time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if exp, ok := r.expires[id]; ok && exp.After(now) {
+		return false, nil
+	}
+	r.expires[id] = now.Add(ttl)
+	return true, nil
+}
+
+func (r *DeliveryRepo) ForgetDelivery(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.expires, id)
+	return nil
+}
+
+func (r *DeliveryRepo) DeleteExpiredDeliveries(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, exp := range r.expires {
+		if !exp.After(now) {
+			delete(r.expires, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type DigestRepo struct {
+	mu     sync.Mutex
+	nextID int64
+	events map[int64]repository.DigestEvent
+	sent   map[int64]time.Time
+}
+
+func NewDigestRepo() *DigestRepo {
+	return &DigestRepo{events: make(map[int64]repository.DigestEvent), sent: make(map[int64]time.Time)}
+}
+
+func (r *DigestRepo) AddDigestEvent(e repository.DigestEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	e.ID = r.nextID
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	r.events[e.ID] = e
+	return nil
+}
+
+func (r *DigestRepo) DigestRecipients() ([]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[int64]struct{})
+	var out []int64
+	for _, e := range r.events {
+		if _, ok := seen[e.TelegramID]; ok {
+			continue
+		}
+		seen[e.TelegramID] = struct{}{}
+		out = append(out, e.TelegramID)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
+
+func (r *DigestRepo) PendingDigestEvents(tgID int64, before time.Time) ([]repository.DigestEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []repository.DigestEvent
+	for _, e := range r.events {
+		if e.TelegramID == tgID && e.CreatedAt.Before(before) {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *DigestRepo) DeleteDigestEvents(ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.events, id)
+	}
+	return nil
+}
+
+func (r *DigestRepo) LastDigestSlot(tgID int64) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sent[tgID], nil
+}
+
+func (r *DigestRepo) MarkDigestSent(tgID int64, slot time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sent[tgID] = slot
+	return nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type HeldRepo struct {
+	mu     sync.Mutex
+	nextID int64
+	held   map[int64]repository.HeldNotification
+}
+
+func NewHeldRepo() *HeldRepo {
+	return &HeldRepo{held: make(map[int64]repository.HeldNotification)}
+}
+
+func (r *HeldRepo) Hold(n repository.HeldNotification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	n.ID = r.nextID
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+	r.held[n.ID] = n
+	return nil
+}
+
+func (r *HeldRepo) DueHeld(now time.Time, limit int) ([]repository.HeldNotification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []repository.HeldNotification
+	for _, n := range r.held {
+		if !n.ReleaseAt.After(now) {
+			due = append(due, n)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *HeldRepo) DeleteHeld(ids []int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		delete(r.held, id)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type messageKey struct {
+	chatID    int64
+	messageID int
+}
+
+type MessageRepo struct {
+	mu   sync.RWMutex
+	sent map[messageKey]repository.SentMessage
+}
+
+func NewMessageRepo() *MessageRepo {
+	return &MessageRepo{sent: make(map[messageKey]repository.SentMessage)}
+}
+
+func (r *MessageRepo) SaveSentMessage(m repository.SentMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sent[messageKey{m.ChatID, m.MessageID}] = m
+	return nil
+}
+
+func (r *MessageRepo) GetSentMessage(chatID int64, messageID int) (*repository.SentMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.sent[messageKey{chatID, messageID}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &m, nil
+}
+
+func (r *MessageRepo) ListSentMessagesByPR(repo string, number int) ([]repository.SentMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []repository.SentMessage
+	for _, m := range r.sent {
+		if m.Ref.Repo == repo && m.Ref.Number == number && m.EditedAt.IsZero() {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ChatID != out[j].ChatID {
+			return out[i].ChatID < out[j].ChatID
+		}
+		return out[i].MessageID < out[j].MessageID
+	})
+	return out, nil
+}
+
+func (r *MessageRepo) MarkSentMessageEdited(chatID int64, messageID int, at time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := messageKey{chatID, messageID}
+	m, ok := r.sent[key]
+	if !ok {
+		return false, repository.ErrNotFound
+	}
+	if !m.EditedAt.IsZero() {
+		return false, nil
+	}
+	m.EditedAt = at
+	r.sent[key] = m
+	return true, nil
+}
+
+func (r *MessageRepo) UnmarkSentMessageEdited(chatID int64, messageID int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := messageKey{chatID, messageID}
+	m, ok := r.sent[key]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if m.EditedAt.Equal(at) {
+		m.EditedAt = time.Time{}
+		r.sent[key] = m
+	}
+	return nil
+}
+
+func (r *MessageRepo) DeleteSentMessagesBefore(t time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, m := range r.sent {
+		if m.SentAt.Before(t) {
+			delete(r.sent, k)
+		}
+	}
+	return nil
+}
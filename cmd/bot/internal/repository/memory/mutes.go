@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type muteKey struct {
+	tgID   int64
+	target string
+}
+
+type MuteRepo struct {
+	mu    sync.RWMutex
+	mutes map[muteKey]repository.Mute
+}
+
+func NewMuteRepo() *MuteRepo {
+	return &MuteRepo{mutes: make(map[muteKey]repository.Mute)}
+}
+
+func (r *MuteRepo) SaveMute(m repository.Mute) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m.Target = strings.ToLower(m.Target)
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now()
+	}
+	r.mutes[muteKey{m.TelegramID, m.Target}] = m
+	return nil
+}
+
+func (r *MuteRepo) DeleteMute(tgID int64, target string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := muteKey{tgID, strings.ToLower(target)}
+	_, ok := r.mutes[key]
+	delete(r.mutes, key)
+	return ok, nil
+}
+
+func (r *MuteRepo) ListMutes(tgID int64, now time.Time) ([]repository.Mute, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []repository.Mute
+	for k, m := range r.mutes {
+		if k.tgID != tgID || (!m.ExpiresAt.IsZero() && !m.ExpiresAt.After(now)) {
+			continue
+		}
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Target < out[j].Target })
+	return out, nil
+}
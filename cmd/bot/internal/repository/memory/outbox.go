@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type OutboxRepo struct {
+	mu     sync.Mutex
+	nextID int64
+	queue  map[int64]repository.OutboxMessage
+	dead   []repository.OutboxMessage
+}
+
+func NewOutboxRepo() *OutboxRepo {
+	return &OutboxRepo{queue: make(map[int64]repository.OutboxMessage)}
+}
+
+func (r *OutboxRepo) Enqueue(msg repository.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	msg.ID = r.nextID
+	now := time.Now()
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = now
+	}
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = now
+	}
+	r.queue[msg.ID] = msg
+	return nil
+}
+
+func (r *OutboxRepo) ClaimDue(now time.Time, lease time.Duration, limit int) ([]repository.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []repository.OutboxMessage
+	for _, m := range r.queue {
+		if !m.NextAttemptAt.After(now) {
+			due = append(due, m)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		r.queue[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (r *OutboxRepo) MarkSent(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.queue, id)
+	return nil
+}
+
+func (r *OutboxRepo) Reschedule(id int64, attempts int, next time.Time, lastErr string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.queue[id]
+	if !ok {
+		return ErrNotFound
+	}
+	m.Attempts = attempts
+	m.NextAttemptAt = next
+	m.LastError = lastErr
+	r.queue[id] = m
+	return nil
+}
+
+func (r *OutboxRepo) MoveToDeadLetter(id int64, attempts int, lastErr string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.queue[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.queue, id)
+
+	m.Attempts = attempts
+	m.LastError = lastErr
+	m.FailedAt = time.Now()
+	r.dead = append(r.dead, m)
+	return nil
+}
+
+func (r *OutboxRepo) ListDeadLetters(limit int) ([]repository.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]repository.OutboxMessage, 0, len(r.dead))
+	for i := len(r.dead) - 1; i >= 0; i-- {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, r.dead[i])
+	}
+	return out, nil
+}
+
+func (r *OutboxRepo) ListPendingByPR(repo string, number int) ([]repository.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []repository.OutboxMessage
+	for _, m := range r.queue {
+		if m.Ref != nil && m.Ref.Repo == repo && m.Ref.Number == number {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (r *OutboxRepo) SetNote(id int64, note string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.queue[id]
+	if !ok {
+		return ErrNotFound
+	}
+	m.Note = note
+	r.queue[id] = m
+	return nil
+}
+
+func (r *OutboxRepo) GetNote(id int64) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.queue[id]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return m.Note, nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type prKey struct {
+	repo   string
+	number int
+}
+
+type PullRequestRepo struct {
+	mu  sync.RWMutex
+	prs map[prKey]repository.PullRequest
+}
+
+func NewPullRequestRepo() *PullRequestRepo {
+	return &PullRequestRepo{prs: make(map[prKey]repository.PullRequest)}
+}
+
+func (r *PullRequestRepo) SavePullRequest(pr repository.PullRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pr.Repo = strings.ToLower(pr.Repo)
+	r.prs[prKey{pr.Repo, pr.Number}] = clonePullRequest(pr)
+	return nil
+}
+
+func (r *PullRequestRepo) GetPullRequest(repo string, number int) (*repository.PullRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pr, ok := r.prs[prKey{strings.ToLower(repo), number}]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	pr = clonePullRequest(pr)
+	return &pr, nil
+}
+
+func (r *PullRequestRepo) ListOpenPullRequests() ([]repository.PullRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []repository.PullRequest
+	for _, pr := range r.prs {
+		if pr.State == repository.PRStateOpen {
+			out = append(out, clonePullRequest(pr))
+		}
+	}
+	sortPullRequests(out)
+	return out, nil
+}
+
+func (r *PullRequestRepo) ListByHeadSHA(repo, sha string) ([]repository.PullRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	repo = strings.ToLower(repo)
+	var out []repository.PullRequest
+	for _, pr := range r.prs {
+		if pr.State == repository.PRStateOpen && pr.Repo == repo && pr.HeadSHA == sha {
+			out = append(out, clonePullRequest(pr))
+		}
+	}
+	sortPullRequests(out)
+	return out, nil
+}
+
+func (r *PullRequestRepo) ListReviewRequests(userID int64, login string) ([]repository.PullRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	login = normalizeLogin(login)
+	var out []repository.PullRequest
+	for _, pr := range r.prs {
+		if pr.State != repository.PRStateOpen {
+			continue
+		}
+		for _, rv := range pr.Reviewers {
+			if rv.Requested && sameGitHubUser(rv.UserID, rv.Login, userID, login) {
+				out = append(out, clonePullRequest(pr))
+				break
+			}
+		}
+	}
+	sortPullRequests(out)
+	return out, nil
+}
+
+func (r *PullRequestRepo) ListAuthoredPullRequests(userID int64, login string) ([]repository.PullRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	login = normalizeLogin(login)
+	var out []repository.PullRequest
+	for _, pr := range r.prs {
+		if pr.State == repository.PRStateOpen && sameGitHubUser(pr.AuthorID, pr.AuthorLogin, userID, login) {
+			out = append(out, clonePullRequest(pr))
+		}
+	}
+	sortPullRequests(out)
+	return out, nil
+}
+
+func sortPullRequests(prs []repository.PullRequest) {
+	sort.Slice(prs, func(i, j int) bool {
+		if prs[i].Repo != prs[j].Repo {
+			return prs[i].Repo < prs[j].Repo
+		}
+		return prs[i].Number < prs[j].Number
+	})
+}
+
+func clonePullRequest(pr repository.PullRequest) repository.PullRequest {
+	pr.Assignees = append([]string(nil), pr.Assignees...)
+	pr.AssigneeIDs = append([]int64(nil), pr.AssigneeIDs...)
+	pr.Reviewers = append([]repository.PullRequestReviewer(nil), pr.Reviewers...)
+	if pr.Mergeable != nil {
+		m := *pr.Mergeable
+		pr.Mergeable = &m
+	}
+	return pr
+}
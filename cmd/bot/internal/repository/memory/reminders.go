@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type ReminderRepo struct {
+	mu     sync.Mutex
+	nextID int64
+	byID   map[int64]repository.ReviewReminder
+}
+
+func NewReminderRepo() *ReminderRepo {
+	return &ReminderRepo{byID: make(map[int64]repository.ReviewReminder)}
+}
+
+func (r *ReminderRepo) GetReminder(repo string, number int, reviewerID int64, reviewer string) (*repository.ReviewReminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repo, reviewer = strings.ToLower(repo), normalizeLogin(reviewer)
+	var found *repository.ReviewReminder
+	for _, rem := range r.byID {
+		if rem.Repo != repo || rem.Number != number || !sameGitHubUser(rem.ReviewerID, rem.Reviewer, reviewerID, reviewer) {
+			continue
+		}
+		// совпадение по ID важнее совпадения по логину
+		if found == nil || (reviewerID != 0 && rem.ReviewerID == reviewerID) {
+			rem := rem
+			found = &rem
+		}
+	}
+	if found == nil {
+		return nil, repository.ErrNotFound
+	}
+	return found, nil
+}
+
+func (r *ReminderRepo) GetReminderByID(id int64) (*repository.ReviewReminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rem, ok := r.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &rem, nil
+}
+
+func (r *ReminderRepo) SaveReminder(rem repository.ReviewReminder) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rem.Repo = strings.ToLower(rem.Repo)
+	rem.Reviewer = normalizeLogin(rem.Reviewer)
+	if _, ok := r.byID[rem.ID]; !ok {
+		r.nextID++
+		rem.ID = r.nextID
+	}
+	r.byID[rem.ID] = rem
+	return rem.ID, nil
+}
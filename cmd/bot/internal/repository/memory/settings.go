@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type SettingsRepo struct {
+	mu   sync.RWMutex
+	byTG map[int64]repository.UserSettings
+}
+
+func NewSettingsRepo() *SettingsRepo {
+	return &SettingsRepo{byTG: make(map[int64]repository.UserSettings)}
+}
+
+func (r *SettingsRepo) GetSettings(tgID int64) (repository.UserSettings, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.byTG[tgID]
+	if !ok {
+		return repository.UserSettings{TelegramID: tgID}, nil
+	}
+	s.DisabledEvents = append([]string(nil), s.DisabledEvents...)
+	return s, nil
+}
+
+func (r *SettingsRepo) SaveSettings(settings repository.UserSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	settings.DisabledEvents = append([]string(nil), settings.DisabledEvents...)
+	r.byTG[settings.TelegramID] = settings
+	return nil
+}
+
+func (r *SettingsRepo) DigestUsers() ([]int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []int64
+	for id, s := range r.byTG {
+		if s.DigestEnabled {
+			out = append(out, id)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out, nil
+}
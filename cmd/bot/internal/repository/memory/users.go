@@ -48,8 +48,10 @@ func (r *UserRepo) SaveBinding(binding repository.UserBinding) error {
 	}
 
 	r.byTG[binding.TelegramID] = repository.UserBinding{
-		TelegramID:  binding.TelegramID,
-		GitHubLogin: login,
+		TelegramID:   binding.TelegramID,
+		GitHubLogin:  login,
+		GitHubUserID: binding.GitHubUserID,
+		Verified:     binding.Verified,
 	}
 
 	set, ok := r.byLogin[login]
@@ -90,3 +92,26 @@ func (r *UserRepo) GetByGitHubLogin(login string) ([]repository.UserBinding, err
 	}
 	return out, nil
 }
+
+func (r *UserRepo) GetByGitHubUser(id int64, login string) ([]repository.UserBinding, error) {
+	login = normalizeLogin(login)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []repository.UserBinding
+	for _, b := range r.byTG {
+		if sameGitHubUser(b.GitHubUserID, b.GitHubLogin, id, login) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// sameGitHubUser сравнивает сохранённого пользователя с искомым: по ID, а по логину —
+// только если у одной из сторон ID неизвестен. Логины должны быть нормализованы.
+func sameGitHubUser(storedID int64, storedLogin string, id int64, login string) bool {
+	byID := id != 0 && storedID == id
+	byLogin := login != "" && storedLogin == login && (id == 0 || storedID == 0 || storedID == id)
+	return byID || byLogin
+}
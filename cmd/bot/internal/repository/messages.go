@@ -0,0 +1,35 @@
+package repository
+
+import "time"
+
+// MessageRef — к чему относится сообщение в Telegram: PR, тип события и комментарий.
+type MessageRef struct {
+	Repo      string `json:"repo"`
+	Number    int    `json:"number"`
+	Kind      string `json:"kind,omitempty"`
+	CommentID int64  `json:"comment_id,omitempty"` // review comment, на который можно ответить
+}
+
+// SentMessage — отправленное сообщение: по нему находят, на что ответил пользователь,
+// и правят его, когда PR влит, закрыт или уже получил review.
+type SentMessage struct {
+	ChatID    int64
+	MessageID int
+	Ref       MessageRef
+	Text      string
+	SentAt    time.Time
+	EditedAt  time.Time // нулевое — сообщение ещё не правили
+}
+
+type MessageRepository interface {
+	SaveSentMessage(m SentMessage) error
+	GetSentMessage(chatID int64, messageID int) (*SentMessage, error)
+	// ListSentMessagesByPR возвращает непоправленные сообщения о PR во всех чатах.
+	ListSentMessagesByPR(repo string, number int) ([]SentMessage, error)
+	// MarkSentMessageEdited отмечает сообщение поправленным и сообщает, отметил ли его этот
+	// вызов: уже отмеченное сообщение второй раз не правят.
+	MarkSentMessageEdited(chatID int64, messageID int, at time.Time) (bool, error)
+	// UnmarkSentMessageEdited снимает отметку at, если правка не удалась.
+	UnmarkSentMessageEdited(chatID int64, messageID int, at time.Time) error
+	DeleteSentMessagesBefore(t time.Time) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import "time"
+
+// Mute — пользователь не хочет уведомлений по PR ("owner/repo#12") или по всему репозиторию ("owner/repo").
+type Mute struct {
+	TelegramID int64
+	Target     string    // в нижнем регистре
+	ExpiresAt  time.Time // нулевое — бессрочно
+	CreatedAt  time.Time
+}
+
+type MuteRepository interface {
+	// SaveMute добавляет mute или продлевает существующий.
+	SaveMute(m Mute) error
+	// DeleteMute снимает mute и сообщает, был ли он.
+	DeleteMute(tgID int64, target string) (bool, error)
+	// ListMutes возвращает mute пользователя, действующие в момент now.
+	ListMutes(tgID int64, now time.Time) ([]Mute, error)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import "time"
+
+// OutboxMessage — исходящее Telegram-сообщение, ожидающее отправки.
+type OutboxMessage struct {
+	ID            int64
+	ChatID        int64
+	Text          string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	CreatedAt     time.Time
+	FailedAt      time.Time // заполняется только для dead letters
+	Buttons       [][]OutboxButton
+	Ref           *MessageRef // nil — сообщение ни к чему не привязано
+	// Note — пометка устаревшего сообщения: его зачёркивают с ней сразу после отправки.
+	Note string
+}
+
+// OutboxButton — inline-кнопка под сообщением: ссылка (URL) или callback (Data).
+type OutboxButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url,omitempty"`
+	Data string `json:"data,omitempty"`
+}
+
+type OutboxRepository interface {
+	Enqueue(msg OutboxMessage) error
+	// ClaimDue забирает до limit сообщений, которые пора отправить, и откладывает их
+	// до now+lease, чтобы параллельный воркер не взял их повторно.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]OutboxMessage, error)
+	MarkSent(id int64) error
+	Reschedule(id int64, attempts int, next time.Time, lastErr string) error
+	// MoveToDeadLetter убирает сообщение из очереди в таблицу недоставленных.
+	MoveToDeadLetter(id int64, attempts int, lastErr string) error
+	ListDeadLetters(limit int) ([]OutboxMessage, error)
+	// ListPendingByPR возвращает ещё не отправленные сообщения о PR во всех чатах.
+	ListPendingByPR(repo string, number int) ([]OutboxMessage, error)
+	SetNote(id int64, note string) error
+	// GetNote возвращает текущую пометку сообщения, пока оно в очереди.
+	GetNote(id int64) (string, error)
+}
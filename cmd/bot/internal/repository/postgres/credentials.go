@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// префикс зашифрованного токена; строки без него сохранены до шифрования
+const tokenPrefix = "v1:"
+
+// CredentialsRepo хранит OAuth-токены зашифрованными AES-GCM.
+type CredentialsRepo struct {
+	pool *pgxpool.Pool
+	aead cipher.AEAD
+}
+
+// NewCredentialsRepo создаёт хранилище токенов; ключ AES-256 выводится из key.
+func NewCredentialsRepo(pool *pgxpool.Pool, key string) (*CredentialsRepo, error) {
+	if key == "" {
+		return nil, fmt.Errorf("credentials: empty encryption key")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("credentials: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("credentials: %w", err)
+	}
+	return &CredentialsRepo{pool: pool, aead: aead}, nil
+}
+
+func (r *CredentialsRepo) SaveCredentials(c repository.GitHubCredentials) error {
+	const q = `
+INSERT INTO github_credentials (telegram_id, github_user_id, github_login, access_token)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (telegram_id) DO UPDATE SET
+  github_user_id = EXCLUDED.github_user_id,
+  github_login   = EXCLUDED.github_login,
+  access_token   = EXCLUDED.access_token,
+  created_at     = now();
+`
+	token, err := r.encrypt(c.AccessToken)
+	if err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	_, err = r.pool.Exec(context.Background(), q, c.TelegramID, c.GitHubUserID, normalizeLogin(c.GitHubLogin), token)
+	if err != nil {
+		return fmt.Errorf("save credentials: %w", err)
+	}
+	return nil
+}
+
+func (r *CredentialsRepo) GetCredentials(tgID int64) (*repository.GitHubCredentials, error) {
+	const q = `
+SELECT telegram_id, github_user_id, github_login, access_token, created_at
+FROM github_credentials
+WHERE telegram_id = $1;
+`
+	var c repository.GitHubCredentials
+	err := r.pool.QueryRow(context.Background(), q, tgID).
+		Scan(&c.TelegramID, &c.GitHubUserID, &c.GitHubLogin, &c.AccessToken, &c.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("get credentials: %w", err)
+	}
+	if c.AccessToken, err = r.decrypt(c.AccessToken); err != nil {
+		// ключ сменился или запись испорчена: токен не восстановить, нужен новый /link
+		return nil, fmt.Errorf("get credentials for %d: %v: %w", tgID, err, repository.ErrNotFound)
+	}
+	return &c, nil
+}
+
+func (r *CredentialsRepo) DeleteCredentials(tgID int64) error {
+	const q = `DELETE FROM github_credentials WHERE telegram_id = $1;`
+	if _, err := r.pool.Exec(context.Background(), q, tgID); err != nil {
+		return fmt.Errorf("delete credentials: %w", err)
+	}
+	return nil
+}
+
+func (r *CredentialsRepo) encrypt(token string) (string, error) {
+	nonce := make([]byte, r.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("encrypt access token: %w", err)
+	}
+	sealed := r.aead.Seal(nonce, nonce, []byte(token), nil)
+	return tokenPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (r *CredentialsRepo) decrypt(stored string) (string, error) {
+	if !strings.HasPrefix(stored, tokenPrefix) {
+		// сохранён до шифрования; перешифруется при следующем /link
+		return stored, nil
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(stored, tokenPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode access token: %w", err)
+	}
+	n := r.aead.NonceSize()
+	if len(sealed) < n {
+		return "", fmt.Errorf("decrypt access token: too short")
+	}
+	token, err := r.aead.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt access token: %w", err)
+	}
+	return string(token), nil
+}
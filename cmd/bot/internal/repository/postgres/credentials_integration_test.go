@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestCredentialsRepo_SaveGetDelete(t *testing.T) {
+	pool := newTestPool(t)
+	repo, err := NewCredentialsRepo(pool, "test-key")
+	if err != nil {
+		t.Fatalf("NewCredentialsRepo: %v", err)
+	}
+
+	tgID := time.Now().UnixNano()
+	err = repo.SaveCredentials(repository.GitHubCredentials{
+		TelegramID:   tgID,
+		GitHubUserID: 42,
+		GitHubLogin:  "Octocat",
+		AccessToken:  "tok",
+	})
+	if err != nil {
+		t.Fatalf("SaveCredentials: %v", err)
+	}
+
+	got, err := repo.GetCredentials(tgID)
+	if err != nil {
+		t.Fatalf("GetCredentials: %v", err)
+	}
+	if got.GitHubUserID != 42 || got.GitHubLogin != "octocat" || got.AccessToken != "tok" {
+		t.Fatalf("unexpected credentials: %+v", got)
+	}
+
+	var stored string
+	if err := pool.QueryRow(context.Background(), `SELECT access_token FROM github_credentials WHERE telegram_id = $1`, tgID).Scan(&stored); err != nil {
+		t.Fatalf("select access_token: %v", err)
+	}
+	if stored == "tok" || !strings.HasPrefix(stored, tokenPrefix) {
+		t.Fatalf("expected encrypted token in db, got %q", stored)
+	}
+
+	// с другим ключом токен не расшифровать — как будто его нет
+	other, err := NewCredentialsRepo(pool, "other-key")
+	if err != nil {
+		t.Fatalf("NewCredentialsRepo: %v", err)
+	}
+	if _, err := other.GetCredentials(tgID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound with a wrong key, got %v", err)
+	}
+
+	if err := repo.DeleteCredentials(tgID); err != nil {
+		t.Fatalf("DeleteCredentials: %v", err)
+	}
+	if _, err := repo.GetCredentials(tgID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
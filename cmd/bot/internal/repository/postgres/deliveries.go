@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DeliveryRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeliveryRepo(pool *pgxpool.Pool) *DeliveryRepo {
+	return &DeliveryRepo{pool: pool}
+}
+
+func (r *DeliveryRepo) MarkDelivery(id string, now time.Time, ttl time.Duration) (bool, error) {
+	// просроченная запись перезаписывается, живая — нет (тогда RETURNING пустой)
+	const q = `
+INSERT INTO webhook_deliveries (id, expires_at)
+VALUES ($1, $2)
+ON CONFLICT (id) DO UPDATE SET expires_at = EXCLUDED.expires_at
+WHERE webhook_deliveries.expires_at <= $3
+RETURNING id;
+`
+	var got string
+	err := r.pool.QueryRow(context.Background(), q, id, now.Add(ttl), now).Scan(&got)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("mark delivery: %w", err)
+	}
+	return true, nil
+}
+
+func (r *DeliveryRepo) ForgetDelivery(id string) error {
+	const q = `DELETE FROM webhook_deliveries WHERE id = $1;`
+	if _, err := r.pool.Exec(context.Background(), q, id); err != nil {
+		return fmt.Errorf("forget delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *DeliveryRepo) DeleteExpiredDeliveries(now time.Time) error {
+	const q = `DELETE FROM webhook_deliveries WHERE expires_at <= $1;`
+	if _, err := r.pool.Exec(context.Background(), q, now); err != nil {
+		return fmt.Errorf("delete expired deliveries: %w", err)
+	}
+	return nil
+}
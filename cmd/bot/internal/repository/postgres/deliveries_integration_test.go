@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeliveryRepo_MarkDelivery(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewDeliveryRepo(pool)
+
+	id := fmt.Sprintf("delivery-%d", time.Now().UnixNano())
+	now := time.Now()
+
+	first, err := repo.MarkDelivery(id, now, time.Hour)
+	if err != nil {
+		t.Fatalf("MarkDelivery: %v", err)
+	}
+	if !first {
+		t.Fatalf("expected first delivery")
+	}
+
+	again, err := repo.MarkDelivery(id, now.Add(time.Minute), time.Hour)
+	if err != nil {
+		t.Fatalf("MarkDelivery: %v", err)
+	}
+	if again {
+		t.Fatalf("expected duplicate delivery")
+	}
+
+	expired, err := repo.MarkDelivery(id, now.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("MarkDelivery: %v", err)
+	}
+	if !expired {
+		t.Fatalf("expected delivery to be accepted after ttl")
+	}
+
+	if err := repo.ForgetDelivery(id); err != nil {
+		t.Fatalf("ForgetDelivery: %v", err)
+	}
+	forgotten, err := repo.MarkDelivery(id, now.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("MarkDelivery: %v", err)
+	}
+	if !forgotten {
+		t.Fatalf("expected forgotten delivery to be accepted again")
+	}
+
+	if err := repo.DeleteExpiredDeliveries(now.Add(4 * time.Hour)); err != nil {
+		t.Fatalf("DeleteExpiredDeliveries: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type DigestRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewDigestRepo(pool *pgxpool.Pool) *DigestRepo {
+	return &DigestRepo{pool: pool}
+}
+
+func (r *DigestRepo) AddDigestEvent(e repository.DigestEvent) error {
+	const q = `
+INSERT INTO digest_events (telegram_id, kind, repo, number, title, url, actor, text, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, now()));
+`
+	var created *time.Time
+	if !e.CreatedAt.IsZero() {
+		created = &e.CreatedAt
+	}
+	_, err := r.pool.Exec(context.Background(), q,
+		e.TelegramID, e.Kind, e.Repo, e.Number, e.Title, e.URL, e.Actor, e.Text, created,
+	)
+	if err != nil {
+		return fmt.Errorf("add digest event: %w", err)
+	}
+	return nil
+}
+
+func (r *DigestRepo) DigestRecipients() ([]int64, error) {
+	const q = `SELECT DISTINCT telegram_id FROM digest_events ORDER BY telegram_id;`
+	rows, err := r.pool.Query(context.Background(), q)
+	if err != nil {
+		return nil, fmt.Errorf("select digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan digest recipient: %w", err)
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest recipients: %w", err)
+	}
+	return out, nil
+}
+
+func (r *DigestRepo) PendingDigestEvents(tgID int64, before time.Time) ([]repository.DigestEvent, error) {
+	const q = `
+SELECT id, telegram_id, kind, repo, number, title, url, actor, text, created_at
+FROM digest_events
+WHERE telegram_id = $1 AND created_at < $2
+ORDER BY id;
+`
+	rows, err := r.pool.Query(context.Background(), q, tgID, before)
+	if err != nil {
+		return nil, fmt.Errorf("select digest events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []repository.DigestEvent
+	for rows.Next() {
+		var e repository.DigestEvent
+		if err := rows.Scan(&e.ID, &e.TelegramID, &e.Kind, &e.Repo, &e.Number, &e.Title, &e.URL, &e.Actor, &e.Text, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan digest event: %w", err)
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest events: %w", err)
+	}
+	return out, nil
+}
+
+func (r *DigestRepo) DeleteDigestEvents(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	const q = `DELETE FROM digest_events WHERE id = ANY($1);`
+	if _, err := r.pool.Exec(context.Background(), q, ids); err != nil {
+		return fmt.Errorf("delete digest events: %w", err)
+	}
+	return nil
+}
+
+func (r *DigestRepo) LastDigestSlot(tgID int64) (time.Time, error) {
+	const q = `SELECT slot FROM digest_sent WHERE telegram_id = $1;`
+	var slot time.Time
+	if err := r.pool.QueryRow(context.Background(), q, tgID).Scan(&slot); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("get last digest slot: %w", err)
+	}
+	return slot, nil
+}
+
+func (r *DigestRepo) MarkDigestSent(tgID int64, slot time.Time) error {
+	const q = `
+INSERT INTO digest_sent (telegram_id, slot)
+VALUES ($1, $2)
+ON CONFLICT (telegram_id) DO UPDATE SET slot = GREATEST(digest_sent.slot, EXCLUDED.slot);
+`
+	if _, err := r.pool.Exec(context.Background(), q, tgID, slot); err != nil {
+		return fmt.Errorf("mark digest sent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestDigestRepo_PendingAndDelete(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewDigestRepo(pool)
+
+	tgID := time.Now().UnixNano()
+	now := time.Now()
+
+	for _, e := range []repository.DigestEvent{
+		{TelegramID: tgID, Kind: "approved", Repo: "org/repo", Number: 1, Title: "old", CreatedAt: now.Add(-time.Hour)},
+		{TelegramID: tgID, Kind: "approved", Repo: "org/repo", Number: 2, Title: "new", CreatedAt: now.Add(time.Hour)},
+	} {
+		if err := repo.AddDigestEvent(e); err != nil {
+			t.Fatalf("AddDigestEvent: %v", err)
+		}
+	}
+
+	users, err := repo.DigestRecipients()
+	if err != nil {
+		t.Fatalf("DigestRecipients: %v", err)
+	}
+	found := false
+	for _, id := range users {
+		found = found || id == tgID
+	}
+	if !found {
+		t.Fatalf("expected %d among digest recipients", tgID)
+	}
+
+	pending, err := repo.PendingDigestEvents(tgID, now)
+	if err != nil {
+		t.Fatalf("PendingDigestEvents: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Title != "old" || pending[0].Repo != "org/repo" {
+		t.Fatalf("unexpected pending events %+v", pending)
+	}
+
+	if err := repo.DeleteDigestEvents([]int64{pending[0].ID}); err != nil {
+		t.Fatalf("DeleteDigestEvents: %v", err)
+	}
+	pending, err = repo.PendingDigestEvents(tgID, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("PendingDigestEvents: %v", err)
+	}
+	if len(pending) != 1 || pending[0].Title != "new" {
+		t.Fatalf("unexpected pending events after delete %+v", pending)
+	}
+}
+
+func TestDigestRepo_LastSlot(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewDigestRepo(pool)
+
+	tgID := time.Now().UnixNano()
+	last, err := repo.LastDigestSlot(tgID)
+	if err != nil || !last.IsZero() {
+		t.Fatalf("LastDigestSlot = %v, %v; want zero", last, err)
+	}
+
+	slot := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	for _, s := range []time.Time{slot, slot.AddDate(0, 0, -1)} {
+		if err := repo.MarkDigestSent(tgID, s); err != nil {
+			t.Fatalf("MarkDigestSent: %v", err)
+		}
+	}
+	// более старый слот не откатывает отметку
+	last, err = repo.LastDigestSlot(tgID)
+	if err != nil || !last.Equal(slot) {
+		t.Fatalf("LastDigestSlot = %v, %v; want %v", last, err, slot)
+	}
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type HeldRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewHeldRepo(pool *pgxpool.Pool) *HeldRepo {
+	return &HeldRepo{pool: pool}
+}
+
+func (r *HeldRepo) Hold(n repository.HeldNotification) error {
+	const q = `
+INSERT INTO held_notifications (telegram_id, text, release_at)
+VALUES ($1, $2, $3);
+`
+	if _, err := r.pool.Exec(context.Background(), q, n.TelegramID, n.Text, n.ReleaseAt); err != nil {
+		return fmt.Errorf("hold notification: %w", err)
+	}
+	return nil
+}
+
+func (r *HeldRepo) DueHeld(now time.Time, limit int) ([]repository.HeldNotification, error) {
+	const q = `
+SELECT id, telegram_id, text, created_at, release_at
+FROM held_notifications
+WHERE release_at <= $1
+ORDER BY id
+LIMIT $2;
+`
+	rows, err := r.pool.Query(context.Background(), q, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select held notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var out []repository.HeldNotification
+	for rows.Next() {
+		var n repository.HeldNotification
+		if err := rows.Scan(&n.ID, &n.TelegramID, &n.Text, &n.CreatedAt, &n.ReleaseAt); err != nil {
+			return nil, fmt.Errorf("scan held notification: %w", err)
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate held notifications: %w", err)
+	}
+	return out, nil
+}
+
+func (r *HeldRepo) DeleteHeld(ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	const q = `DELETE FROM held_notifications WHERE id = ANY($1);`
+	if _, err := r.pool.Exec(context.Background(), q, ids); err != nil {
+		return fmt.Errorf("delete held notifications: %w", err)
+	}
+	return nil
+}
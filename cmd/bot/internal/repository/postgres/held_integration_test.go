@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestHeldRepo_DueAndDelete(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewHeldRepo(pool)
+
+	tgID := time.Now().UnixNano()
+	now := time.Now()
+
+	if err := repo.Hold(repository.HeldNotification{TelegramID: tgID, Text: "due", ReleaseAt: now.Add(-time.Minute)}); err != nil {
+		t.Fatalf("Hold: %v", err)
+	}
+	if err := repo.Hold(repository.HeldNotification{TelegramID: tgID, Text: "later", ReleaseAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Hold: %v", err)
+	}
+
+	due, err := repo.DueHeld(now, 1000)
+	if err != nil {
+		t.Fatalf("DueHeld: %v", err)
+	}
+	var ids []int64
+	for _, n := range due {
+		if n.TelegramID != tgID {
+			continue
+		}
+		if n.Text != "due" {
+			t.Fatalf("unexpected due notification %+v", n)
+		}
+		ids = append(ids, n.ID)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("expected 1 due notification, got %d", len(ids))
+	}
+
+	if err := repo.DeleteHeld(ids); err != nil {
+		t.Fatalf("DeleteHeld: %v", err)
+	}
+	due, err = repo.DueHeld(now.Add(2*time.Hour), 1000)
+	if err != nil {
+		t.Fatalf("DueHeld: %v", err)
+	}
+	for _, n := range due {
+		if n.TelegramID == tgID && n.Text != "later" {
+			t.Fatalf("deleted notification returned again: %+v", n)
+		}
+	}
+}
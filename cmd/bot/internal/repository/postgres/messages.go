@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type MessageRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewMessageRepo(pool *pgxpool.Pool) *MessageRepo {
+	return &MessageRepo{pool: pool}
+}
+
+func (r *MessageRepo) SaveSentMessage(m repository.SentMessage) error {
+	const q = `
+INSERT INTO sent_messages (chat_id, message_id, repo, number, kind, comment_id, text, sent_at, edited_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, now()), $9)
+ON CONFLICT (chat_id, message_id) DO UPDATE SET
+  repo = EXCLUDED.repo,
+  number = EXCLUDED.number,
+  kind = EXCLUDED.kind,
+  comment_id = EXCLUDED.comment_id,
+  text = EXCLUDED.text,
+  sent_at = EXCLUDED.sent_at,
+  edited_at = EXCLUDED.edited_at;
+`
+	_, err := r.pool.Exec(context.Background(), q,
+		m.ChatID, m.MessageID, m.Ref.Repo, m.Ref.Number, m.Ref.Kind, m.Ref.CommentID, m.Text,
+		nullTime(m.SentAt), nullTime(m.EditedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("save sent message: %w", err)
+	}
+	return nil
+}
+
+func (r *MessageRepo) GetSentMessage(chatID int64, messageID int) (*repository.SentMessage, error) {
+	const q = `
+SELECT chat_id, message_id, repo, number, kind, comment_id, text, sent_at, edited_at
+FROM sent_messages
+WHERE chat_id = $1 AND message_id = $2;
+`
+	m, err := scanSentMessage(r.pool.QueryRow(context.Background(), q, chatID, messageID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("get sent message: %w", err)
+	}
+	return &m, nil
+}
+
+func (r *MessageRepo) ListSentMessagesByPR(repo string, number int) ([]repository.SentMessage, error) {
+	const q = `
+SELECT chat_id, message_id, repo, number, kind, comment_id, text, sent_at, edited_at
+FROM sent_messages
+WHERE repo = $1 AND number = $2 AND edited_at IS NULL
+ORDER BY chat_id, message_id;
+`
+	rows, err := r.pool.Query(context.Background(), q, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("list sent messages: %w", err)
+	}
+	defer rows.Close()
+
+	var out []repository.SentMessage
+	for rows.Next() {
+		m, err := scanSentMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *MessageRepo) MarkSentMessageEdited(chatID int64, messageID int, at time.Time) (bool, error) {
+	// отметка ставится только на неотмеченное сообщение: из двух параллельных правок
+	// сообщение достаётся одной
+	const q = `
+WITH target AS (
+  SELECT 1 FROM sent_messages WHERE chat_id = $1 AND message_id = $2
+), marked AS (
+  UPDATE sent_messages SET edited_at = $3
+  WHERE chat_id = $1 AND message_id = $2 AND edited_at IS NULL
+  RETURNING 1
+)
+SELECT EXISTS (SELECT 1 FROM target), EXISTS (SELECT 1 FROM marked);
+`
+	var found, marked bool
+	if err := r.pool.QueryRow(context.Background(), q, chatID, messageID, at.Truncate(time.Microsecond)).Scan(&found, &marked); err != nil {
+		return false, fmt.Errorf("mark sent message edited: %w", err)
+	}
+	if !found {
+		return false, repository.ErrNotFound
+	}
+	return marked, nil
+}
+
+func (r *MessageRepo) UnmarkSentMessageEdited(chatID int64, messageID int, at time.Time) error {
+	// PostgreSQL хранит время с точностью до микросекунды — сравниваем так же
+	const q = `UPDATE sent_messages SET edited_at = NULL WHERE chat_id = $1 AND message_id = $2 AND edited_at = $3;`
+	if _, err := r.pool.Exec(context.Background(), q, chatID, messageID, at.Truncate(time.Microsecond)); err != nil {
+		return fmt.Errorf("unmark sent message edited: %w", err)
+	}
+	return nil
+}
+
+func scanSentMessage(row pgx.Row) (repository.SentMessage, error) {
+	var (
+		m        repository.SentMessage
+		editedAt *time.Time
+	)
+	err := row.Scan(
+		&m.ChatID, &m.MessageID, &m.Ref.Repo, &m.Ref.Number, &m.Ref.Kind, &m.Ref.CommentID, &m.Text, &m.SentAt, &editedAt,
+	)
+	if editedAt != nil {
+		m.EditedAt = *editedAt
+	}
+	return m, err
+}
+
+func (r *MessageRepo) DeleteSentMessagesBefore(t time.Time) error {
+	const q = `DELETE FROM sent_messages WHERE sent_at < $1;`
+	if _, err := r.pool.Exec(context.Background(), q, t); err != nil {
+		return fmt.Errorf("delete old sent messages: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestMessageRepo_SaveGetDelete(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewMessageRepo(pool)
+
+	chatID := time.Now().UnixNano()
+	sentAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	ref := repository.MessageRef{Repo: "org/repo", Number: 5, Kind: "review_comment", CommentID: 77}
+
+	if err := repo.SaveSentMessage(repository.SentMessage{ChatID: chatID, MessageID: 10, Ref: ref, SentAt: sentAt}); err != nil {
+		t.Fatalf("SaveSentMessage: %v", err)
+	}
+
+	got, err := repo.GetSentMessage(chatID, 10)
+	if err != nil {
+		t.Fatalf("GetSentMessage: %v", err)
+	}
+	if got.Ref != ref || !got.SentAt.Equal(sentAt) {
+		t.Fatalf("unexpected sent message %+v", got)
+	}
+
+	if err := repo.DeleteSentMessagesBefore(sentAt.Add(time.Minute)); err != nil {
+		t.Fatalf("DeleteSentMessagesBefore: %v", err)
+	}
+	if _, err := repo.GetSentMessage(chatID, 10); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestMessageRepo_ListByPRAndMarkEdited(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewMessageRepo(pool)
+
+	chatID := time.Now().UnixNano()
+	repoName := "org/edits-" + time.Now().Format("150405.000000")
+	for id, number := range map[int]int{1: 5, 2: 5, 3: 6} {
+		m := repository.SentMessage{
+			ChatID:    chatID,
+			MessageID: id,
+			Ref:       repository.MessageRef{Repo: repoName, Number: number, Kind: "review_requested"},
+			Text:      "review please",
+		}
+		if err := repo.SaveSentMessage(m); err != nil {
+			t.Fatalf("SaveSentMessage: %v", err)
+		}
+	}
+
+	got, err := repo.ListSentMessagesByPR(repoName, 5)
+	if err != nil {
+		t.Fatalf("ListSentMessagesByPR: %v", err)
+	}
+	if len(got) != 2 || got[0].MessageID != 1 || got[1].MessageID != 2 || got[0].Text != "review please" {
+		t.Fatalf("unexpected messages %+v", got)
+	}
+
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	if marked, err := repo.MarkSentMessageEdited(chatID, 1, at); err != nil || !marked {
+		t.Fatalf("MarkSentMessageEdited: %v, %v", marked, err)
+	}
+	// второй раз сообщение не отмечается: его уже правит первый
+	if marked, err := repo.MarkSentMessageEdited(chatID, 1, at.Add(time.Second)); err != nil || marked {
+		t.Fatalf("expected message to be marked once, got %v, %v", marked, err)
+	}
+	got, err = repo.ListSentMessagesByPR(repoName, 5)
+	if err != nil {
+		t.Fatalf("ListSentMessagesByPR: %v", err)
+	}
+	if len(got) != 1 || got[0].MessageID != 2 {
+		t.Fatalf("expected edited message to be skipped, got %+v", got)
+	}
+	if _, err := repo.MarkSentMessageEdited(chatID, 99, time.Now()); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// правка не удалась — отметка снимается, и сообщение снова ждёт пометки
+	if err := repo.UnmarkSentMessageEdited(chatID, 1, at); err != nil {
+		t.Fatalf("UnmarkSentMessageEdited: %v", err)
+	}
+	if got, err = repo.ListSentMessagesByPR(repoName, 5); err != nil || len(got) != 2 {
+		t.Fatalf("expected unmarked message to be listed again, got %+v (%v)", got, err)
+	}
+}
@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type MuteRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewMuteRepo(pool *pgxpool.Pool) *MuteRepo {
+	return &MuteRepo{pool: pool}
+}
+
+func (r *MuteRepo) SaveMute(m repository.Mute) error {
+	const q = `
+INSERT INTO mutes (telegram_id, target, expires_at, created_at)
+VALUES ($1, $2, $3, COALESCE($4, now()))
+ON CONFLICT (telegram_id, target) DO UPDATE SET expires_at = EXCLUDED.expires_at;
+`
+	_, err := r.pool.Exec(context.Background(), q, m.TelegramID, strings.ToLower(m.Target), nullTime(m.ExpiresAt), nullTime(m.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("save mute: %w", err)
+	}
+	return nil
+}
+
+func (r *MuteRepo) DeleteMute(tgID int64, target string) (bool, error) {
+	const q = `DELETE FROM mutes WHERE telegram_id = $1 AND target = $2;`
+	tag, err := r.pool.Exec(context.Background(), q, tgID, strings.ToLower(target))
+	if err != nil {
+		return false, fmt.Errorf("delete mute: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *MuteRepo) ListMutes(tgID int64, now time.Time) ([]repository.Mute, error) {
+	const q = `
+SELECT telegram_id, target, expires_at, created_at
+FROM mutes
+WHERE telegram_id = $1 AND (expires_at IS NULL OR expires_at > $2)
+ORDER BY target;
+`
+	rows, err := r.pool.Query(context.Background(), q, tgID, now)
+	if err != nil {
+		return nil, fmt.Errorf("list mutes: %w", err)
+	}
+	defer rows.Close()
+
+	var out []repository.Mute
+	for rows.Next() {
+		var (
+			m         repository.Mute
+			expiresAt *time.Time
+		)
+		if err := rows.Scan(&m.TelegramID, &m.Target, &expiresAt, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		if expiresAt != nil {
+			m.ExpiresAt = *expiresAt
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
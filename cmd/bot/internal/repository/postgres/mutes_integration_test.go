@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestMuteRepo_SaveListDelete(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewMuteRepo(pool)
+
+	tgID := time.Now().UnixNano()
+	now := time.Now()
+
+	mutes := []repository.Mute{
+		{TelegramID: tgID, Target: "Org/Repo#5"},
+		{TelegramID: tgID, Target: "org/other", ExpiresAt: now.Add(time.Hour)},
+		{TelegramID: tgID, Target: "org/expired", ExpiresAt: now.Add(-time.Hour)},
+	}
+	for _, m := range mutes {
+		if err := repo.SaveMute(m); err != nil {
+			t.Fatalf("SaveMute: %v", err)
+		}
+	}
+
+	got, err := repo.ListMutes(tgID, now)
+	if err != nil {
+		t.Fatalf("ListMutes: %v", err)
+	}
+	if len(got) != 2 || got[0].Target != "org/other" || got[1].Target != "org/repo#5" || !got[1].ExpiresAt.IsZero() {
+		t.Fatalf("unexpected mutes %+v", got)
+	}
+
+	ok, err := repo.DeleteMute(tgID, "org/repo#5")
+	if err != nil || !ok {
+		t.Fatalf("DeleteMute: ok=%v err=%v", ok, err)
+	}
+	if ok, _ := repo.DeleteMute(tgID, "org/repo#5"); ok {
+		t.Fatalf("expected second delete to report nothing deleted")
+	}
+}
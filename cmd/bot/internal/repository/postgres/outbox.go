@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type OutboxRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewOutboxRepo(pool *pgxpool.Pool) *OutboxRepo {
+	return &OutboxRepo{pool: pool}
+}
+
+func (r *OutboxRepo) Enqueue(msg repository.OutboxMessage) error {
+	const q = `
+INSERT INTO outbox (chat_id, text, next_attempt_at, buttons, ref)
+VALUES ($1, $2, COALESCE($3, now()), COALESCE($4::jsonb, '[]'::jsonb), $5::jsonb);
+`
+	var next *time.Time
+	if !msg.NextAttemptAt.IsZero() {
+		next = &msg.NextAttemptAt
+	}
+	buttons := msg.Buttons
+	if buttons == nil {
+		buttons = [][]repository.OutboxButton{}
+	}
+	if _, err := r.pool.Exec(context.Background(), q, msg.ChatID, msg.Text, next, buttons, msg.Ref); err != nil {
+		return fmt.Errorf("enqueue outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepo) ClaimDue(now time.Time, lease time.Duration, limit int) ([]repository.OutboxMessage, error) {
+	const q = `
+UPDATE outbox SET next_attempt_at = $2
+WHERE id IN (
+  SELECT id FROM outbox
+  WHERE next_attempt_at <= $1
+  ORDER BY next_attempt_at, id
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, chat_id, text, attempts, next_attempt_at, last_error, created_at, buttons, ref, note;
+`
+	rows, err := r.pool.Query(context.Background(), q, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox messages: %w", err)
+	}
+	return scanOutbox(rows)
+}
+
+func (r *OutboxRepo) MarkSent(id int64) error {
+	const q = `DELETE FROM outbox WHERE id = $1;`
+	if _, err := r.pool.Exec(context.Background(), q, id); err != nil {
+		return fmt.Errorf("mark outbox message sent: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepo) Reschedule(id int64, attempts int, next time.Time, lastErr string) error {
+	const q = `
+UPDATE outbox SET attempts = $2, next_attempt_at = $3, last_error = $4
+WHERE id = $1;
+`
+	tag, err := r.pool.Exec(context.Background(), q, id, attempts, next, lastErr)
+	if err != nil {
+		return fmt.Errorf("reschedule outbox message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *OutboxRepo) MoveToDeadLetter(id int64, attempts int, lastErr string) error {
+	const q = `
+WITH moved AS (
+  DELETE FROM outbox WHERE id = $1
+  RETURNING id, chat_id, text, created_at
+)
+INSERT INTO outbox_dead_letters (id, chat_id, text, attempts, last_error, created_at)
+SELECT id, chat_id, text, $2, $3, created_at FROM moved;
+`
+	tag, err := r.pool.Exec(context.Background(), q, id, attempts, lastErr)
+	if err != nil {
+		return fmt.Errorf("move outbox message to dead letters: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *OutboxRepo) ListDeadLetters(limit int) ([]repository.OutboxMessage, error) {
+	const q = `
+SELECT id, chat_id, text, attempts, failed_at, last_error, created_at
+FROM outbox_dead_letters
+ORDER BY failed_at DESC
+LIMIT $1;
+`
+	rows, err := r.pool.Query(context.Background(), q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var out []repository.OutboxMessage
+	for rows.Next() {
+		var m repository.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Text, &m.Attempts, &m.FailedAt, &m.LastError, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *OutboxRepo) ListPendingByPR(repo string, number int) ([]repository.OutboxMessage, error) {
+	const q = `
+SELECT id, chat_id, text, attempts, next_attempt_at, last_error, created_at, buttons, ref, note
+FROM outbox
+WHERE ref->>'repo' = $1 AND (ref->>'number')::int = $2
+ORDER BY id;
+`
+	rows, err := r.pool.Query(context.Background(), q, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("list pending outbox messages: %w", err)
+	}
+	return scanOutbox(rows)
+}
+
+func (r *OutboxRepo) SetNote(id int64, note string) error {
+	const q = `UPDATE outbox SET note = $2 WHERE id = $1;`
+	tag, err := r.pool.Exec(context.Background(), q, id, note)
+	if err != nil {
+		return fmt.Errorf("set outbox message note: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *OutboxRepo) GetNote(id int64) (string, error) {
+	const q = `SELECT note FROM outbox WHERE id = $1;`
+	var note string
+	if err := r.pool.QueryRow(context.Background(), q, id).Scan(&note); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", repository.ErrNotFound
+		}
+		return "", fmt.Errorf("get outbox message note: %w", err)
+	}
+	return note, nil
+}
+
+func scanOutbox(rows pgx.Rows) ([]repository.OutboxMessage, error) {
+	defer rows.Close()
+
+	var out []repository.OutboxMessage
+	for rows.Next() {
+		var m repository.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.ChatID, &m.Text, &m.Attempts, &m.NextAttemptAt, &m.LastError, &m.CreatedAt, &m.Buttons, &m.Ref, &m.Note); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
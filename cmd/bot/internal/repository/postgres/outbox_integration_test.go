@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func claimFor(t *testing.T, repo *OutboxRepo, chatID int64, now time.Time) *repository.OutboxMessage {
+	t.Helper()
+
+	msgs, err := repo.ClaimDue(now, time.Minute, 1000)
+	if err != nil {
+		t.Fatalf("ClaimDue: %v", err)
+	}
+	for _, m := range msgs {
+		if m.ChatID == chatID {
+			return &m
+		}
+	}
+	return nil
+}
+
+func TestOutboxRepo_ClaimAndMarkSent(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewOutboxRepo(pool)
+
+	chatID := time.Now().UnixNano()
+	if err := repo.Enqueue(repository.OutboxMessage{ChatID: chatID, Text: "hello"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+	m := claimFor(t, repo, chatID, now)
+	if m == nil {
+		t.Fatalf("message was not claimed")
+	}
+	if m.Text != "hello" || m.Attempts != 0 {
+		t.Fatalf("unexpected message: %+v", m)
+	}
+
+	// повторно не выдаётся, пока действует lease
+	if again := claimFor(t, repo, chatID, now); again != nil {
+		t.Fatalf("message claimed twice")
+	}
+
+	if err := repo.MarkSent(m.ID); err != nil {
+		t.Fatalf("MarkSent: %v", err)
+	}
+	if again := claimFor(t, repo, chatID, now.Add(time.Hour)); again != nil {
+		t.Fatalf("sent message is still in outbox")
+	}
+}
+
+func TestOutboxRepo_RescheduleAndDeadLetter(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewOutboxRepo(pool)
+
+	chatID := time.Now().UnixNano()
+	if err := repo.Enqueue(repository.OutboxMessage{ChatID: chatID, Text: "x"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	now := time.Now().Add(time.Second)
+	m := claimFor(t, repo, chatID, now)
+	if m == nil {
+		t.Fatalf("message was not claimed")
+	}
+
+	if err := repo.Reschedule(m.ID, 1, now.Add(time.Hour), "boom"); err != nil {
+		t.Fatalf("Reschedule: %v", err)
+	}
+	m = claimFor(t, repo, chatID, now.Add(2*time.Hour))
+	if m == nil || m.Attempts != 1 || m.LastError != "boom" {
+		t.Fatalf("unexpected rescheduled message: %+v", m)
+	}
+
+	if err := repo.MoveToDeadLetter(m.ID, 2, "boom again"); err != nil {
+		t.Fatalf("MoveToDeadLetter: %v", err)
+	}
+	if again := claimFor(t, repo, chatID, now.Add(3*time.Hour)); again != nil {
+		t.Fatalf("dead-lettered message is still in outbox")
+	}
+
+	dead, err := repo.ListDeadLetters(100)
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	found := false
+	for _, d := range dead {
+		if d.ID == m.ID && d.ChatID == chatID && d.Attempts == 2 {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("dead letter %d not found", m.ID)
+	}
+}
+
+func TestOutboxRepo_PendingNotes(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewOutboxRepo(pool)
+
+	chatID := time.Now().UnixNano()
+	ref := &repository.MessageRef{Repo: "org/pending", Number: int(chatID % 100000), Kind: "review_requested"}
+	if err := repo.Enqueue(repository.OutboxMessage{ChatID: chatID, Text: "review please", Ref: ref}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	pending, err := repo.ListPendingByPR(ref.Repo, ref.Number)
+	if err != nil {
+		t.Fatalf("ListPendingByPR: %v", err)
+	}
+	if len(pending) != 1 || pending[0].ChatID != chatID || pending[0].Ref == nil || pending[0].Ref.Kind != ref.Kind {
+		t.Fatalf("unexpected pending messages %+v", pending)
+	}
+
+	if err := repo.SetNote(pending[0].ID, "merged"); err != nil {
+		t.Fatalf("SetNote: %v", err)
+	}
+	note, err := repo.GetNote(pending[0].ID)
+	if err != nil || note != "merged" {
+		t.Fatalf("GetNote = %q, %v", note, err)
+	}
+
+	if err := repo.MarkSent(pending[0].ID); err != nil {
+		t.Fatalf("MarkSent: %v", err)
+	}
+	if _, err := repo.GetNote(pending[0].ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a sent message, got %v", err)
+	}
+	if err := repo.SetNote(pending[0].ID, "merged"); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a sent message, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type PullRequestRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPullRequestRepo(pool *pgxpool.Pool) *PullRequestRepo {
+	return &PullRequestRepo{pool: pool}
+}
+
+func (r *PullRequestRepo) SavePullRequest(pr repository.PullRequest) error {
+	ctx := context.Background()
+	repo := strings.ToLower(pr.Repo)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin save pull request: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	const upsert = `
+INSERT INTO pull_requests (
+  repo, number, github_id, title, url, author_login, author_id, assignees, assignee_ids,
+  draft, state, head_sha, mergeable, created_at, updated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+ON CONFLICT (repo, number) DO UPDATE SET
+  github_id    = EXCLUDED.github_id,
+  title        = EXCLUDED.title,
+  url          = EXCLUDED.url,
+  author_login = EXCLUDED.author_login,
+  author_id    = EXCLUDED.author_id,
+  assignees    = EXCLUDED.assignees,
+  assignee_ids = EXCLUDED.assignee_ids,
+  draft        = EXCLUDED.draft,
+  state        = EXCLUDED.state,
+  head_sha     = EXCLUDED.head_sha,
+  mergeable    = EXCLUDED.mergeable,
+  created_at   = EXCLUDED.created_at,
+  updated_at   = EXCLUDED.updated_at;
+`
+	assignees := pr.Assignees
+	if assignees == nil {
+		assignees = []string{}
+	}
+	assigneeIDs := make([]int64, len(assignees))
+	copy(assigneeIDs, pr.AssigneeIDs)
+	_, err = tx.Exec(ctx, upsert,
+		repo, pr.Number, pr.GitHubID, pr.Title, pr.URL, pr.AuthorLogin, pr.AuthorID, assignees, assigneeIDs,
+		pr.Draft, pr.State, pr.HeadSHA, pr.Mergeable, pr.CreatedAt, pr.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert pull request: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM pull_request_reviewers WHERE repo = $1 AND number = $2;`, repo, pr.Number); err != nil {
+		return fmt.Errorf("delete pull request reviewers: %w", err)
+	}
+
+	const insertReviewer = `
+INSERT INTO pull_request_reviewers (repo, number, login, user_id, requested, requested_at, state, reviewed_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+`
+	for _, rv := range pr.Reviewers {
+		_, err := tx.Exec(ctx, insertReviewer,
+			repo, pr.Number, rv.Login, rv.UserID, rv.Requested, nullTime(rv.RequestedAt), rv.State, nullTime(rv.ReviewedAt),
+		)
+		if err != nil {
+			return fmt.Errorf("insert pull request reviewer %s: %w", rv.Login, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit save pull request: %w", err)
+	}
+	return nil
+}
+
+func (r *PullRequestRepo) GetPullRequest(repo string, number int) (*repository.PullRequest, error) {
+	prs, err := r.queryPullRequests(`WHERE repo = $1 AND number = $2`, strings.ToLower(repo), number)
+	if err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &prs[0], nil
+}
+
+func (r *PullRequestRepo) ListOpenPullRequests() ([]repository.PullRequest, error) {
+	return r.queryPullRequests(`WHERE state = $1`, repository.PRStateOpen)
+}
+
+func (r *PullRequestRepo) ListReviewRequests(userID int64, login string) ([]repository.PullRequest, error) {
+	const where = `
+WHERE state = $1 AND EXISTS (
+  SELECT 1 FROM pull_request_reviewers r
+  WHERE r.repo = pull_requests.repo AND r.number = pull_requests.number AND r.requested
+    AND (($2::bigint <> 0 AND r.user_id = $2::bigint)
+      OR ($3 <> '' AND r.login = $3 AND ($2::bigint = 0 OR r.user_id = 0 OR r.user_id = $2::bigint)))
+)`
+	return r.queryPullRequests(where, repository.PRStateOpen, userID, normalizeLogin(login))
+}
+
+func (r *PullRequestRepo) ListAuthoredPullRequests(userID int64, login string) ([]repository.PullRequest, error) {
+	const where = `
+WHERE state = $1
+  AND (($2::bigint <> 0 AND author_id = $2::bigint)
+    OR ($3 <> '' AND author_login = $3 AND ($2::bigint = 0 OR author_id = 0 OR author_id = $2::bigint)))`
+	return r.queryPullRequests(where, repository.PRStateOpen, userID, normalizeLogin(login))
+}
+
+func (r *PullRequestRepo) ListByHeadSHA(repo, sha string) ([]repository.PullRequest, error) {
+	return r.queryPullRequests(`WHERE state = $1 AND repo = $2 AND head_sha = $3`, repository.PRStateOpen, strings.ToLower(repo), sha)
+}
+
+// queryPullRequests выбирает PR по условию where и подтягивает их ревьюеров.
+func (r *PullRequestRepo) queryPullRequests(where string, args ...any) ([]repository.PullRequest, error) {
+	ctx := context.Background()
+
+	q := `
+SELECT repo, number, github_id, title, url, author_login, author_id, assignees, assignee_ids,
+       draft, state, head_sha, mergeable, created_at, updated_at
+FROM pull_requests
+` + where + `
+ORDER BY repo, number;
+`
+	rows, err := r.pool.Query(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select pull requests: %w", err)
+	}
+	prs, err := scanPullRequests(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan pull requests: %w", err)
+	}
+	if len(prs) == 0 {
+		return prs, nil
+	}
+
+	if err := r.loadReviewers(ctx, prs); err != nil {
+		return nil, err
+	}
+	return prs, nil
+}
+
+func (r *PullRequestRepo) loadReviewers(ctx context.Context, prs []repository.PullRequest) error {
+	repos := make([]string, len(prs))
+	numbers := make([]int, len(prs))
+	index := make(map[string]int, len(prs))
+	for i, pr := range prs {
+		repos[i], numbers[i] = pr.Repo, pr.Number
+		index[fmt.Sprintf("%s#%d", pr.Repo, pr.Number)] = i
+	}
+
+	const q = `
+SELECT r.repo, r.number, r.login, r.user_id, r.requested, r.requested_at, r.state, r.reviewed_at
+FROM pull_request_reviewers r
+JOIN unnest($1::text[], $2::int[]) AS k(repo, number) ON k.repo = r.repo AND k.number = r.number
+ORDER BY r.repo, r.number, r.login, r.user_id;
+`
+	rows, err := r.pool.Query(ctx, q, repos, numbers)
+	if err != nil {
+		return fmt.Errorf("select pull request reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			repo                    string
+			number                  int
+			rv                      repository.PullRequestReviewer
+			requestedAt, reviewedAt *time.Time
+		)
+		if err := rows.Scan(&repo, &number, &rv.Login, &rv.UserID, &rv.Requested, &requestedAt, &rv.State, &reviewedAt); err != nil {
+			return fmt.Errorf("scan pull request reviewer: %w", err)
+		}
+		if requestedAt != nil {
+			rv.RequestedAt = *requestedAt
+		}
+		if reviewedAt != nil {
+			rv.ReviewedAt = *reviewedAt
+		}
+		i, ok := index[fmt.Sprintf("%s#%d", repo, number)]
+		if !ok {
+			continue
+		}
+		prs[i].Reviewers = append(prs[i].Reviewers, rv)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate pull request reviewers: %w", err)
+	}
+	return nil
+}
+
+func scanPullRequests(rows pgx.Rows) ([]repository.PullRequest, error) {
+	defer rows.Close()
+
+	var out []repository.PullRequest
+	for rows.Next() {
+		var pr repository.PullRequest
+		err := rows.Scan(
+			&pr.Repo, &pr.Number, &pr.GitHubID, &pr.Title, &pr.URL, &pr.AuthorLogin, &pr.AuthorID, &pr.Assignees, &pr.AssigneeIDs,
+			&pr.Draft, &pr.State, &pr.HeadSHA, &pr.Mergeable, &pr.CreatedAt, &pr.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
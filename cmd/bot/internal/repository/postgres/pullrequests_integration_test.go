@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestPullRequestRepo_SaveAndGet(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewPullRequestRepo(pool)
+
+	name := fmt.Sprintf("org/repo_%d", time.Now().UnixNano())
+	now := time.Now().UTC().Truncate(time.Second)
+	mergeable := true
+
+	pr := repository.PullRequest{
+		Repo:        name,
+		Number:      7,
+		GitHubID:    100,
+		Title:       "Fix",
+		URL:         "https://github.com/" + name + "/pull/7",
+		AuthorLogin: "author",
+		AuthorID:    1,
+		Assignees:   []string{"owner"},
+		AssigneeIDs: []int64{2},
+		State:       repository.PRStateOpen,
+		HeadSHA:     "abc",
+		Mergeable:   &mergeable,
+		CreatedAt:   now.Add(-time.Hour),
+		UpdatedAt:   now,
+		Reviewers: []repository.PullRequestReviewer{
+			{Login: "alice", UserID: 10, State: "approved", ReviewedAt: now},
+			{Login: "bob", UserID: 11, Requested: true, RequestedAt: now},
+		},
+	}
+	if err := repo.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+
+	got, err := repo.GetPullRequest(name, 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest: %v", err)
+	}
+	if got.Title != "Fix" || got.HeadSHA != "abc" || got.Mergeable == nil || !*got.Mergeable || len(got.Assignees) != 1 {
+		t.Fatalf("unexpected pull request %+v", got)
+	}
+	if len(got.AssigneeIDs) != 1 || got.AssigneeIDs[0] != 2 {
+		t.Fatalf("unexpected assignee IDs %+v", got.AssigneeIDs)
+	}
+	if len(got.Reviewers) != 2 || got.Reviewers[0].State != "approved" || !got.Reviewers[1].Requested {
+		t.Fatalf("unexpected reviewers %+v", got.Reviewers)
+	}
+
+	pr.State = repository.PRStateMerged
+	pr.Reviewers = pr.Reviewers[:1]
+	if err := repo.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+	got, err = repo.GetPullRequest(name, 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest: %v", err)
+	}
+	if got.State != repository.PRStateMerged || len(got.Reviewers) != 1 {
+		t.Fatalf("expected merged PR with 1 reviewer, got %+v", got)
+	}
+
+	if _, err := repo.GetPullRequest(name, 8); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestPullRequestRepo_ListReviewRequests(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewPullRequestRepo(pool)
+
+	suffix := time.Now().UnixNano()
+	name := fmt.Sprintf("org/repo_%d", suffix)
+	login := fmt.Sprintf("reviewer_%d", suffix)
+	now := time.Now().UTC()
+
+	for number, rv := range map[int]repository.PullRequestReviewer{
+		1: {Login: login, UserID: suffix, Requested: true, RequestedAt: now},
+		2: {Login: login, UserID: suffix, State: "approved", ReviewedAt: now},
+		3: {Login: login, Requested: true, RequestedAt: now},
+	} {
+		pr := repository.PullRequest{
+			Repo: name, Number: number, State: repository.PRStateOpen,
+			CreatedAt: now, UpdatedAt: now,
+			Reviewers: []repository.PullRequestReviewer{rv},
+		}
+		if err := repo.SavePullRequest(pr); err != nil {
+			t.Fatalf("SavePullRequest: %v", err)
+		}
+	}
+
+	got, err := repo.ListReviewRequests(suffix, login)
+	if err != nil {
+		t.Fatalf("ListReviewRequests: %v", err)
+	}
+	if len(got) != 2 || got[0].Number != 1 || got[1].Number != 3 {
+		t.Fatalf("expected PRs 1 and 3, got %+v", got)
+	}
+}
+
+func TestPullRequestRepo_ListAuthoredPullRequests(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewPullRequestRepo(pool)
+
+	suffix := time.Now().UnixNano()
+	name := fmt.Sprintf("org/repo_%d", suffix)
+	login := fmt.Sprintf("author_%d", suffix)
+	now := time.Now().UTC()
+
+	for number, state := range map[int]string{1: repository.PRStateOpen, 2: repository.PRStateMerged} {
+		pr := repository.PullRequest{
+			Repo: name, Number: number, State: state, AuthorLogin: login, AuthorID: suffix,
+			CreatedAt: now, UpdatedAt: now,
+		}
+		if err := repo.SavePullRequest(pr); err != nil {
+			t.Fatalf("SavePullRequest: %v", err)
+		}
+	}
+
+	got, err := repo.ListAuthoredPullRequests(suffix, login)
+	if err != nil {
+		t.Fatalf("ListAuthoredPullRequests: %v", err)
+	}
+	if len(got) != 1 || got[0].Number != 1 {
+		t.Fatalf("expected only open PR 1, got %+v", got)
+	}
+}
+
+func TestPullRequestRepo_ListByHeadSHA(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewPullRequestRepo(pool)
+
+	name := fmt.Sprintf("org/repo_%d", time.Now().UnixNano())
+	now := time.Now().UTC()
+
+	prs := []repository.PullRequest{
+		{Repo: name, Number: 1, State: repository.PRStateOpen, HeadSHA: "abc"},
+		{Repo: name, Number: 2, State: repository.PRStateClosed, HeadSHA: "abc"},
+		{Repo: name, Number: 3, State: repository.PRStateOpen, HeadSHA: "def"},
+	}
+	for _, pr := range prs {
+		pr.CreatedAt, pr.UpdatedAt = now, now
+		if err := repo.SavePullRequest(pr); err != nil {
+			t.Fatalf("SavePullRequest: %v", err)
+		}
+	}
+
+	got, err := repo.ListByHeadSHA(name, "abc")
+	if err != nil {
+		t.Fatalf("ListByHeadSHA: %v", err)
+	}
+	if len(got) != 1 || got[0].Number != 1 {
+		t.Fatalf("expected only open PR 1, got %+v", got)
+	}
+}
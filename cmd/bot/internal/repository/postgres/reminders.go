@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type ReminderRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewReminderRepo(pool *pgxpool.Pool) *ReminderRepo {
+	return &ReminderRepo{pool: pool}
+}
+
+const reminderColumns = `id, repo, number, reviewer, reviewer_id, requested_at, reminded_at, escalated_at, lead_escalated_at, snoozed_until`
+
+func (r *ReminderRepo) GetReminder(repo string, number int, reviewerID int64, reviewer string) (*repository.ReviewReminder, error) {
+	q := `SELECT ` + reminderColumns + ` FROM review_reminders
+WHERE repo = $1 AND number = $2
+  AND (($3::bigint <> 0 AND reviewer_id = $3::bigint)
+    OR ($4 <> '' AND reviewer = $4 AND ($3::bigint = 0 OR reviewer_id = 0 OR reviewer_id = $3::bigint)))
+ORDER BY ($3::bigint <> 0 AND reviewer_id = $3::bigint) DESC, id
+LIMIT 1;`
+	return r.getOne(q, strings.ToLower(repo), number, reviewerID, normalizeLogin(reviewer))
+}
+
+func (r *ReminderRepo) GetReminderByID(id int64) (*repository.ReviewReminder, error) {
+	q := `SELECT ` + reminderColumns + ` FROM review_reminders WHERE id = $1;`
+	return r.getOne(q, id)
+}
+
+func (r *ReminderRepo) getOne(q string, args ...any) (*repository.ReviewReminder, error) {
+	var (
+		rem                                    repository.ReviewReminder
+		remindedAt, escalatedAt, leadEscalated *time.Time
+		snoozedFor                             *time.Time
+	)
+	err := r.pool.QueryRow(context.Background(), q, args...).Scan(
+		&rem.ID, &rem.Repo, &rem.Number, &rem.Reviewer, &rem.ReviewerID, &rem.RequestedAt, &remindedAt, &escalatedAt, &leadEscalated, &snoozedFor,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("get review reminder: %w", err)
+	}
+	if remindedAt != nil {
+		rem.RemindedAt = *remindedAt
+	}
+	if escalatedAt != nil {
+		rem.EscalatedAt = *escalatedAt
+	}
+	if leadEscalated != nil {
+		rem.LeadEscalatedAt = *leadEscalated
+	}
+	if snoozedFor != nil {
+		rem.SnoozedUntil = *snoozedFor
+	}
+	return &rem, nil
+}
+
+func (r *ReminderRepo) SaveReminder(rem repository.ReviewReminder) (int64, error) {
+	const insert = `
+INSERT INTO review_reminders (
+  repo, number, reviewer, reviewer_id, requested_at, reminded_at, escalated_at, lead_escalated_at, snoozed_until
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id;
+`
+	// логин и ID обновляются вместе с остальным: после переименования запись та же
+	const update = `
+UPDATE review_reminders SET
+  repo = $1, number = $2, reviewer = $3, reviewer_id = $4, requested_at = $5,
+  reminded_at = $6, escalated_at = $7, lead_escalated_at = $8, snoozed_until = $9
+WHERE id = $10
+RETURNING id;
+`
+	args := []any{
+		strings.ToLower(rem.Repo), rem.Number, normalizeLogin(rem.Reviewer), rem.ReviewerID, rem.RequestedAt,
+		nullTime(rem.RemindedAt), nullTime(rem.EscalatedAt), nullTime(rem.LeadEscalatedAt), nullTime(rem.SnoozedUntil),
+	}
+	q := insert
+	if rem.ID != 0 {
+		q = update
+		args = append(args, rem.ID)
+	}
+
+	var id int64
+	if err := r.pool.QueryRow(context.Background(), q, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repository.ErrNotFound
+		}
+		return 0, fmt.Errorf("save review reminder: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestReminderRepo_SaveAndGet(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewReminderRepo(pool)
+
+	name := fmt.Sprintf("org/repo_%d", time.Now().UnixNano())
+	requested := time.Now().UTC().Truncate(time.Second)
+
+	id, err := repo.SaveReminder(repository.ReviewReminder{Repo: name, Number: 1, Reviewer: "Alice", RequestedAt: requested})
+	if err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+
+	rem, err := repo.GetReminder(name, 1, 0, "alice")
+	if err != nil {
+		t.Fatalf("GetReminder: %v", err)
+	}
+	if rem.ID != id || !rem.RemindedAt.IsZero() || !rem.RequestedAt.Equal(requested) {
+		t.Fatalf("unexpected reminder %+v", rem)
+	}
+
+	rem.RemindedAt = requested.Add(time.Hour)
+	again, err := repo.SaveReminder(*rem)
+	if err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+	if again != id {
+		t.Fatalf("expected same id %d, got %d", id, again)
+	}
+
+	byID, err := repo.GetReminderByID(id)
+	if err != nil {
+		t.Fatalf("GetReminderByID: %v", err)
+	}
+	if !byID.RemindedAt.Equal(requested.Add(time.Hour)) {
+		t.Fatalf("expected reminded_at to be saved, got %+v", byID)
+	}
+
+	if _, err := repo.GetReminderByID(-1); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestReminderRepo_GetByReviewerID(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewReminderRepo(pool)
+
+	name := fmt.Sprintf("org/repo_%d", time.Now().UnixNano())
+	id, err := repo.SaveReminder(repository.ReviewReminder{Repo: name, Number: 1, Reviewer: "alice", ReviewerID: 10, RequestedAt: time.Now().UTC()})
+	if err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+
+	// после переименования напоминание находится по ID
+	rem, err := repo.GetReminder(name, 1, 10, "alice-new")
+	if err != nil {
+		t.Fatalf("GetReminder: %v", err)
+	}
+	if rem.ID != id {
+		t.Fatalf("expected reminder %d, got %+v", id, rem)
+	}
+	rem.Reviewer = "alice-new"
+	if _, err := repo.SaveReminder(*rem); err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+	if _, err := repo.GetReminder(name, 1, 0, "alice"); err != repository.ErrNotFound {
+		t.Fatalf("expected old login to be gone, got %v", err)
+	}
+	// старый логин с другим ID — другой человек
+	if _, err := repo.GetReminder(name, 1, 11, "alice-new"); err != repository.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for another user, got %v", err)
+	}
+}
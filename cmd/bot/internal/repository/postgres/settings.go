@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+type SettingsRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewSettingsRepo(pool *pgxpool.Pool) *SettingsRepo {
+	return &SettingsRepo{pool: pool}
+}
+
+func (r *SettingsRepo) GetSettings(tgID int64) (repository.UserSettings, error) {
+	const q = `
+SELECT telegram_id, self_notify, disabled_events, timezone, quiet_start, quiet_end,
+       digest_enabled, digest_time, lang
+FROM user_settings
+WHERE telegram_id = $1;
+`
+	var s repository.UserSettings
+	err := r.pool.QueryRow(context.Background(), q, tgID).Scan(
+		&s.TelegramID, &s.SelfNotify, &s.DisabledEvents, &s.Timezone, &s.QuietStart, &s.QuietEnd,
+		&s.DigestEnabled, &s.DigestTime, &s.Lang,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.UserSettings{TelegramID: tgID}, nil
+		}
+		return repository.UserSettings{}, fmt.Errorf("get settings: %w", err)
+	}
+	return s, nil
+}
+
+func (r *SettingsRepo) SaveSettings(settings repository.UserSettings) error {
+	const q = `
+INSERT INTO user_settings (
+  telegram_id, self_notify, disabled_events, timezone, quiet_start, quiet_end, digest_enabled, digest_time, lang
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (telegram_id) DO UPDATE SET
+  self_notify = EXCLUDED.self_notify,
+  disabled_events = EXCLUDED.disabled_events,
+  timezone = EXCLUDED.timezone,
+  quiet_start = EXCLUDED.quiet_start,
+  quiet_end = EXCLUDED.quiet_end,
+  digest_enabled = EXCLUDED.digest_enabled,
+  digest_time = EXCLUDED.digest_time,
+  lang = EXCLUDED.lang;
+`
+	disabled := settings.DisabledEvents
+	if disabled == nil {
+		disabled = []string{}
+	}
+	_, err := r.pool.Exec(context.Background(), q,
+		settings.TelegramID, settings.SelfNotify, disabled,
+		settings.Timezone, settings.QuietStart, settings.QuietEnd,
+		settings.DigestEnabled, settings.DigestTime, settings.Lang,
+	)
+	if err != nil {
+		return fmt.Errorf("save settings: %w", err)
+	}
+	return nil
+}
+
+func (r *SettingsRepo) DigestUsers() ([]int64, error) {
+	const q = `SELECT telegram_id FROM user_settings WHERE digest_enabled ORDER BY telegram_id;`
+	rows, err := r.pool.Query(context.Background(), q)
+	if err != nil {
+		return nil, fmt.Errorf("select digest users: %w", err)
+	}
+	defer rows.Close()
+
+	var out []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan digest user: %w", err)
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate digest users: %w", err)
+	}
+	return out, nil
+}
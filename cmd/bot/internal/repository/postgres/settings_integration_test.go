@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+func TestSettingsRepo_DefaultsAndSave(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewSettingsRepo(pool)
+
+	tgID := time.Now().UnixNano()
+
+	got, err := repo.GetSettings(tgID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if got.TelegramID != tgID || got.SelfNotify != nil {
+		t.Fatalf("expected default settings, got %+v", got)
+	}
+
+	on := true
+	if err := repo.SaveSettings(repository.UserSettings{TelegramID: tgID, SelfNotify: &on}); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	got, err = repo.GetSettings(tgID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if got.SelfNotify == nil || !*got.SelfNotify {
+		t.Fatalf("expected self_notify=true, got %+v", got.SelfNotify)
+	}
+
+	start, end := 22*60, 8*60
+	got.DisabledEvents = []string{"merged", "ci_failed"}
+	got.Timezone = "Europe/Berlin"
+	got.QuietStart, got.QuietEnd = &start, &end
+	got.Lang = "en"
+	if err := repo.SaveSettings(got); err != nil {
+		t.Fatalf("SaveSettings: %v", err)
+	}
+
+	got, err = repo.GetSettings(tgID)
+	if err != nil {
+		t.Fatalf("GetSettings: %v", err)
+	}
+	if len(got.DisabledEvents) != 2 || got.DisabledEvents[0] != "merged" {
+		t.Fatalf("expected disabled events to be saved, got %v", got.DisabledEvents)
+	}
+	if got.Timezone != "Europe/Berlin" || got.QuietStart == nil || *got.QuietStart != start || got.QuietEnd == nil || *got.QuietEnd != end {
+		t.Fatalf("expected quiet hours to be saved, got %+v", got)
+	}
+	if got.Lang != "en" {
+		t.Fatalf("expected lang en, got %q", got.Lang)
+	}
+}
+
+func TestSettingsRepo_DigestUsers(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewSettingsRepo(pool)
+
+	on, off := time.Now().UnixNano(), time.Now().UnixNano()+1
+	for _, s := range []repository.UserSettings{{TelegramID: on, DigestEnabled: true}, {TelegramID: off}} {
+		if err := repo.SaveSettings(s); err != nil {
+			t.Fatalf("SaveSettings: %v", err)
+		}
+	}
+
+	users, err := repo.DigestUsers()
+	if err != nil {
+		t.Fatalf("DigestUsers: %v", err)
+	}
+	found := map[int64]bool{}
+	for _, id := range users {
+		found[id] = true
+	}
+	if !found[on] || found[off] {
+		t.Fatalf("unexpected digest users %v", users)
+	}
+}
@@ -31,22 +31,25 @@ func (r *UserRepo) SaveBinding(binding repository.UserBinding) error {
 	}
 
 	const q = `
-INSERT INTO user_bindings (telegram_id, github_login)
-VALUES ($1, $2)
-ON CONFLICT (telegram_id) DO UPDATE SET github_login = EXCLUDED.github_login;
+INSERT INTO user_bindings (telegram_id, github_login, github_user_id, verified)
+VALUES ($1, $2, NULLIF($3::bigint, 0), $4)
+ON CONFLICT (telegram_id) DO UPDATE SET
+  github_login   = EXCLUDED.github_login,
+  github_user_id = EXCLUDED.github_user_id,
+  verified       = EXCLUDED.verified;
 `
-	_, err := r.pool.Exec(context.Background(), q, binding.TelegramID, login)
+	_, err := r.pool.Exec(context.Background(), q, binding.TelegramID, login, binding.GitHubUserID, binding.Verified)
 	return err
 }
 
 func (r *UserRepo) GetByTelegramID(tgID int64) (*repository.UserBinding, error) {
 	const q = `
-SELECT telegram_id, github_login
+SELECT telegram_id, github_login, COALESCE(github_user_id, 0), verified
 FROM user_bindings
 WHERE telegram_id = $1;
 `
 	var b repository.UserBinding
-	err := r.pool.QueryRow(context.Background(), q, tgID).Scan(&b.TelegramID, &b.GitHubLogin)
+	err := r.pool.QueryRow(context.Background(), q, tgID).Scan(&b.TelegramID, &b.GitHubLogin, &b.GitHubUserID, &b.Verified)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, repository.ErrNotFound
@@ -60,7 +63,7 @@ func (r *UserRepo) GetByGitHubLogin(login string) ([]repository.UserBinding, err
 	login = normalizeLogin(login)
 
 	const q = `
-SELECT telegram_id, github_login
+SELECT telegram_id, github_login, COALESCE(github_user_id, 0), verified
 FROM user_bindings
 WHERE github_login = $1;
 `
@@ -68,12 +71,32 @@ WHERE github_login = $1;
 	if err != nil {
 		return nil, fmt.Errorf("get by github login: %w", err)
 	}
+	return scanBindings(rows)
+}
+
+func (r *UserRepo) GetByGitHubUser(id int64, login string) ([]repository.UserBinding, error) {
+	login = normalizeLogin(login)
+
+	const q = `
+SELECT telegram_id, github_login, COALESCE(github_user_id, 0), verified
+FROM user_bindings
+WHERE ($1::bigint <> 0 AND github_user_id = $1::bigint)
+   OR ($2 <> '' AND github_login = $2 AND ($1::bigint = 0 OR github_user_id IS NULL OR github_user_id = $1::bigint));
+`
+	rows, err := r.pool.Query(context.Background(), q, id, login)
+	if err != nil {
+		return nil, fmt.Errorf("get by github user: %w", err)
+	}
+	return scanBindings(rows)
+}
+
+func scanBindings(rows pgx.Rows) ([]repository.UserBinding, error) {
 	defer rows.Close()
 
 	var out []repository.UserBinding
 	for rows.Next() {
 		var b repository.UserBinding
-		if err := rows.Scan(&b.TelegramID, &b.GitHubLogin); err != nil {
+		if err := rows.Scan(&b.TelegramID, &b.GitHubLogin, &b.GitHubUserID, &b.Verified); err != nil {
 			return nil, err
 		}
 		out = append(out, b)
@@ -72,3 +72,72 @@ func TestUserRepo_GetByGitHubLogin(t *testing.T) {
 		t.Fatalf("expected 2 bindings, got %d", len(got))
 	}
 }
+
+func TestUserRepo_SaveVerified(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewUserRepo(pool)
+
+	tgID := time.Now().UnixNano()
+	login := fmt.Sprintf("verified_%d", tgID)
+
+	if err := repo.SaveBinding(repository.UserBinding{TelegramID: tgID, GitHubLogin: login, Verified: true}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+
+	got, err := repo.GetByTelegramID(tgID)
+	if err != nil {
+		t.Fatalf("GetByTelegramID: %v", err)
+	}
+	if !got.Verified {
+		t.Fatalf("expected verified binding")
+	}
+
+	// перепривязка через /setgithub снимает подтверждение
+	if err := repo.SaveBinding(repository.UserBinding{TelegramID: tgID, GitHubLogin: login}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	got, err = repo.GetByTelegramID(tgID)
+	if err != nil {
+		t.Fatalf("GetByTelegramID: %v", err)
+	}
+	if got.Verified {
+		t.Fatalf("expected unverified binding")
+	}
+}
+
+func TestUserRepo_GetByGitHubUser(t *testing.T) {
+	pool := newTestPool(t)
+	repo := NewUserRepo(pool)
+
+	suffix := time.Now().UnixNano()
+	login := fmt.Sprintf("user_%d", suffix)
+
+	// привязка с ID, привязка без ID и привязка того же логина, но чужого ID
+	_ = repo.SaveBinding(repository.UserBinding{TelegramID: suffix + 1, GitHubLogin: "renamed_" + login, GitHubUserID: suffix})
+	_ = repo.SaveBinding(repository.UserBinding{TelegramID: suffix + 2, GitHubLogin: login})
+	_ = repo.SaveBinding(repository.UserBinding{TelegramID: suffix + 3, GitHubLogin: login, GitHubUserID: suffix + 100})
+
+	got, err := repo.GetByGitHubUser(suffix, login)
+	if err != nil {
+		t.Fatalf("GetByGitHubUser: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 bindings, got %+v", got)
+	}
+	for _, b := range got {
+		if b.TelegramID == suffix+3 {
+			t.Fatalf("binding with other github user id must not match")
+		}
+		if b.TelegramID == suffix+1 && b.GitHubUserID != suffix {
+			t.Fatalf("expected github user id %d, got %d", suffix, b.GitHubUserID)
+		}
+	}
+
+	byLogin, err := repo.GetByGitHubUser(0, login)
+	if err != nil {
+		t.Fatalf("GetByGitHubUser: %v", err)
+	}
+	if len(byLogin) != 2 {
+		t.Fatalf("expected 2 bindings by login, got %+v", byLogin)
+	}
+}
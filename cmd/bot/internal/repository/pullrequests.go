@@ -0,0 +1,53 @@
+package repository
+
+import "time"
+
+const (
+	PRStateOpen   = "open"
+	PRStateClosed = "closed"
+	PRStateMerged = "merged"
+)
+
+// PullRequest — состояние PR, собранное из webhook.
+type PullRequest struct {
+	Repo        string // owner/name в нижнем регистре
+	Number      int
+	GitHubID    int64
+	Title       string
+	URL         string
+	AuthorLogin string
+	AuthorID    int64
+	Assignees   []string
+	AssigneeIDs []int64 // GitHub ID исполнителей в порядке Assignees; 0 — неизвестен
+	Draft       bool
+	State       string // PRStateOpen, PRStateClosed или PRStateMerged
+	HeadSHA     string
+	Mergeable   *bool // nil, пока GitHub не посчитал
+	CreatedAt   time.Time
+	UpdatedAt   time.Time // updated_at из последнего применённого payload
+	Reviewers   []PullRequestReviewer
+}
+
+// PullRequestReviewer — ревьюер PR: запрошенный сейчас или уже оставивший review.
+type PullRequestReviewer struct {
+	Login       string
+	UserID      int64
+	Requested   bool      // сейчас в requested_reviewers
+	RequestedAt time.Time // когда review запросили последний раз
+	State       string    // последний review: approved, changes_requested, commented; пусто — ещё не было
+	ReviewedAt  time.Time
+}
+
+type PullRequestRepository interface {
+	// SavePullRequest сохраняет PR целиком, включая список ревьюеров.
+	SavePullRequest(pr PullRequest) error
+	GetPullRequest(repo string, number int) (*PullRequest, error)
+	ListOpenPullRequests() ([]PullRequest, error)
+	// ListReviewRequests возвращает открытые PR, где пользователь — запрошенный ревьюер.
+	// Сопоставление как в UserRepository.GetByGitHubUser: по ID, иначе по логину.
+	ListReviewRequests(userID int64, login string) ([]PullRequest, error)
+	// ListAuthoredPullRequests возвращает открытые PR пользователя (сопоставление так же).
+	ListAuthoredPullRequests(userID int64, login string) ([]PullRequest, error)
+	// ListByHeadSHA возвращает открытые PR репозитория, у которых head — коммит sha.
+	ListByHeadSHA(repo, sha string) ([]PullRequest, error)
+}
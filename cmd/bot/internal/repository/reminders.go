@@ -0,0 +1,29 @@
+package repository
+
+import "time"
+
+// ReviewReminder — что уже напомнили ревьюеру по одному запросу review.
+// Повторный запрос review (новый RequestedAt) начинает отсчёт заново.
+type ReviewReminder struct {
+	ID          int64
+	Repo        string
+	Number      int
+	Reviewer    string // логин в нижнем регистре
+	ReviewerID  int64  // GitHub ID ревьюера; 0 — неизвестен
+	RequestedAt time.Time
+	RemindedAt  time.Time
+	EscalatedAt time.Time // автору PR сообщили
+	// LeadEscalatedAt — чату лида сообщили; отдельно от автора, чтобы сбой одного
+	// получателя не повторял эскалацию другому
+	LeadEscalatedAt time.Time
+	SnoozedUntil    time.Time
+}
+
+type ReminderRepository interface {
+	// GetReminder ищет напоминание ревьюеру как UserRepository.GetByGitHubUser: по ID,
+	// иначе по логину, — чтобы переименование в GitHub не начинало отсчёт заново.
+	GetReminder(repo string, number int, reviewerID int64, reviewer string) (*ReviewReminder, error)
+	GetReminderByID(id int64) (*ReviewReminder, error)
+	// SaveReminder сохраняет напоминание (новое, если ID == 0) и возвращает его ID.
+	SaveReminder(r ReviewReminder) (int64, error)
+}
@@ -1,16 +1,60 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var ErrNotFound = errors.New("not found")
 
 type UserBinding struct {
-	TelegramID  int64
-	GitHubLogin string
+	TelegramID   int64
+	GitHubLogin  string
+	GitHubUserID int64 // 0, пока ID неизвестен (например, после /setgithub)
+	Verified     bool  // логин подтверждён через GitHub OAuth (/link)
 }
 
 type UserRepository interface {
 	SaveBinding(binding UserBinding) error
 	GetByGitHubLogin(login string) ([]UserBinding, error)
+	// GetByGitHubUser ищет привязки по ID, а по логину — только среди привязок
+	// без ID или с тем же ID. При id == 0 ищет только по логину.
+	GetByGitHubUser(id int64, login string) ([]UserBinding, error)
 	GetByTelegramID(tgID int64) (*UserBinding, error)
 }
+
+// GitHubCredentials — результат OAuth device flow: подтверждённый аккаунт и его токен.
+type GitHubCredentials struct {
+	TelegramID   int64
+	GitHubUserID int64
+	GitHubLogin  string
+	AccessToken  string
+	CreatedAt    time.Time
+}
+
+type CredentialsRepository interface {
+	SaveCredentials(c GitHubCredentials) error
+	GetCredentials(tgID int64) (*GitHubCredentials, error)
+	DeleteCredentials(tgID int64) error
+}
+
+// UserSettings — персональные настройки пользователя. Nil-поля означают значение по умолчанию.
+type UserSettings struct {
+	TelegramID     int64
+	SelfNotify     *bool
+	DisabledEvents []string // типы событий, которые пользователь отключил в /settings
+	Timezone       string   // IANA-имя, пусто — UTC
+	QuietStart     *int     // начало тихих часов в минутах от полуночи, nil — выключены
+	QuietEnd       *int
+	DigestEnabled  bool   // события копятся и приходят одним сообщением в DigestTime
+	DigestTime     *int   // минуты от полуночи в Timezone, nil — время по умолчанию
+	Lang           string // язык сообщений, пусто — язык по умолчанию
+}
+
+type SettingsRepository interface {
+	// GetSettings возвращает настройки по умолчанию, если пользователь их не менял.
+	GetSettings(tgID int64) (UserSettings, error)
+	SaveSettings(settings UserSettings) error
+	// DigestUsers возвращает пользователей с включённым дайджестом.
+	DigestUsers() ([]int64, error)
+}
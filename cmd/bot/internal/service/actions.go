@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// Префиксы callback-данных кнопок действий под уведомлениями. PR берётся из записи
+// об отправленном сообщении, а в данных лежит его отпечаток (см. ActionData).
+const (
+	ActionMutePrefix    = "pr:mute:"
+	ActionApprovePrefix = "pr:approve:"
+)
+
+var (
+	// ErrNoPullRequest — кнопка нажата под сообщением, которое не относится к PR,
+	// или её данные выписаны для другого PR.
+	ErrNoPullRequest = errors.New("message is not about a pull request")
+	// ErrNotReviewRequest — одобрить можно только из открытого запроса review.
+	ErrNotReviewRequest = errors.New("message is not an open review request")
+)
+
+// ActionData — callback-данные кнопки действия prefix под сообщением о pr. Подпись кнопки
+// покрывает и отпечаток PR, поэтому данные из одного сообщения не сработают под другим.
+func ActionData(prefix string, pr PullRequestRef) string {
+	return prefix + prFingerprint(pr.Repo, pr.Number)
+}
+
+func prFingerprint(repo string, number int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", strings.ToLower(repo), number)))
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+// ReviewApprover — часть GitHub API для одобрения PR.
+type ReviewApprover interface {
+	ApproveReview(ctx context.Context, token, repo string, number int) error
+}
+
+// PullRequestActions выполняет действия кнопок под уведомлениями: заглушить PR и одобрить его.
+type PullRequestActions struct {
+	messages    repository.MessageRepository
+	credentials repository.CredentialsRepository
+	mutes       repository.MuteRepository
+	github      ReviewApprover
+	timeout     time.Duration
+}
+
+// NewPullRequestActions создаёт обработчик действий. gh может быть nil — тогда одобрение недоступно.
+func NewPullRequestActions(
+	messages repository.MessageRepository,
+	credentials repository.CredentialsRepository,
+	mutes repository.MuteRepository,
+	gh ReviewApprover,
+) *PullRequestActions {
+	return &PullRequestActions{messages: messages, credentials: credentials, mutes: mutes, github: gh, timeout: 15 * time.Second}
+}
+
+// Mute выключает уведомления о PR, о котором было сообщение messageID в чате chatID;
+// fingerprint — отпечаток PR из данных кнопки.
+func (a *PullRequestActions) Mute(chatID int64, messageID int, fingerprint string) (PullRequestRef, error) {
+	sent, err := a.sentMessage(chatID, messageID, fingerprint)
+	if err != nil {
+		return PullRequestRef{}, err
+	}
+	ref := PullRequestRef{Repo: sent.Ref.Repo, Number: sent.Ref.Number}
+	if err := a.mutes.SaveMute(repository.Mute{TelegramID: chatID, Target: muteTarget(ref)}); err != nil {
+		return PullRequestRef{}, fmt.Errorf("save mute: %w", err)
+	}
+	return ref, nil
+}
+
+// Approve одобряет PR от имени пользователя, подтвердившего аккаунт через /link.
+// Кнопка работает только под запросом review, который ещё не зачёркнут: PR не влит,
+// не закрыт и пользователь ещё не оставил review.
+func (a *PullRequestActions) Approve(chatID int64, messageID int, fingerprint string) (PullRequestRef, error) {
+	sent, err := a.sentMessage(chatID, messageID, fingerprint)
+	if err != nil {
+		return PullRequestRef{}, err
+	}
+	if sent.Ref.Kind != string(EventReviewRequested) || !sent.EditedAt.IsZero() {
+		return PullRequestRef{}, ErrNotReviewRequest
+	}
+	ref := PullRequestRef{Repo: sent.Ref.Repo, Number: sent.Ref.Number}
+	if a.github == nil {
+		return PullRequestRef{}, ErrNoGitHubToken
+	}
+	token, err := githubToken(a.credentials, chatID)
+	if err != nil {
+		return PullRequestRef{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+	if err := a.github.ApproveReview(ctx, token, ref.Repo, ref.Number); err != nil {
+		return PullRequestRef{}, scopeError(err)
+	}
+	return ref, nil
+}
+
+func (a *PullRequestActions) sentMessage(chatID int64, messageID int, fingerprint string) (*repository.SentMessage, error) {
+	sent, err := a.messages.GetSentMessage(chatID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrNoPullRequest
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get sent message: %w", err)
+	}
+	if sent.Ref.Repo == "" || sent.Ref.Number == 0 {
+		return nil, ErrNoPullRequest
+	}
+	// кнопку из сообщения о другом PR подставили под это сообщение
+	if fingerprint != prFingerprint(sent.Ref.Repo, sent.Ref.Number) {
+		return nil, ErrNoPullRequest
+	}
+	return sent, nil
+}
+
+// githubToken возвращает OAuth-токен пользователя или ErrNoGitHubToken.
+func githubToken(credentials repository.CredentialsRepository, chatID int64) (string, error) {
+	creds, err := credentials.GetCredentials(chatID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return "", ErrNoGitHubToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("get github credentials: %w", err)
+	}
+	if creds.AccessToken == "" {
+		return "", ErrNoGitHubToken
+	}
+	return creds.AccessToken, nil
+}
+
+// muteTarget — ключ mute для PR: "owner/repo#12".
+func muteTarget(ref PullRequestRef) string {
+	return fmt.Sprintf("%s#%d", strings.ToLower(ref.Repo), ref.Number)
+}
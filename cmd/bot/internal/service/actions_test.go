@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/github"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type approverMock struct {
+	approved []string
+	err      error
+}
+
+func (m *approverMock) ApproveReview(_ context.Context, token, repo string, number int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.approved = append(m.approved, fmt.Sprintf("%s %s#%d", token, repo, number))
+	return nil
+}
+
+// buttonSender запоминает кнопки последнего сообщения.
+type buttonSender struct {
+	idSender
+	buttons [][]Button
+}
+
+func (s *buttonSender) SendMessageID(chatID int64, text string, buttons ...[]Button) (int, error) {
+	s.buttons = buttons
+	return s.idSender.SendMessageID(chatID, text, buttons...)
+}
+
+func TestPullRequestActions_MuteAndApprove(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	messages := memory.NewMessageRepo()
+	mutes := memory.NewMuteRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, mutes, NewOutboxSender(outbox), Config{})
+
+	pr := PullRequestRef{Repo: "Org/Repo", Number: 5, URL: "https://github.com/org/repo/pull/5"}
+	n := Notification{Kind: EventReviewRequested, PR: pr, Text: "review please"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	sender := &buttonSender{}
+	w := NewOutboxWorker(outbox, messages, sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(sender.buttons) != 1 || len(sender.buttons[0]) != 3 ||
+		sender.buttons[0][0].URL != pr.URL ||
+		sender.buttons[0][1].Data != ActionData(ActionMutePrefix, pr) ||
+		sender.buttons[0][2].Data != ActionData(ActionApprovePrefix, pr) {
+		t.Fatalf("unexpected buttons %+v", sender.buttons)
+	}
+	fp := strings.TrimPrefix(sender.buttons[0][2].Data, ActionApprovePrefix)
+	otherFP := prFingerprint("org/repo", 6)
+
+	gh := &approverMock{}
+	creds := memory.NewCredentialsRepo()
+	a := NewPullRequestActions(messages, creds, mutes, gh)
+
+	if _, err := a.Approve(2, 1, fp); !errors.Is(err, ErrNoGitHubToken) {
+		t.Fatalf("expected ErrNoGitHubToken, got %v", err)
+	}
+	if err := creds.SaveCredentials(repository.GitHubCredentials{TelegramID: 2, AccessToken: "tok"}); err != nil {
+		t.Fatalf("SaveCredentials: %v", err)
+	}
+	// данные кнопки от сообщения о другом PR не подходят к этому сообщению
+	if _, err := a.Approve(2, 1, otherFP); !errors.Is(err, ErrNoPullRequest) {
+		t.Fatalf("expected ErrNoPullRequest for a foreign fingerprint, got %v", err)
+	}
+	if _, err := a.Approve(2, 1, fp); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if len(gh.approved) != 1 || gh.approved[0] != "tok Org/Repo#5" {
+		t.Fatalf("unexpected approvals %v", gh.approved)
+	}
+
+	// токен без scope repo — пользователю нужен новый /link
+	gh.err = fmt.Errorf("approve pull request: %w", github.ErrInsufficientScope)
+	if _, err := a.Approve(2, 1, fp); !errors.Is(err, ErrNoGitHubScope) {
+		t.Fatalf("expected ErrNoGitHubScope, got %v", err)
+	}
+	gh.err = nil
+	if _, err := a.Approve(1, 1, fp); !errors.Is(err, ErrNoPullRequest) {
+		t.Fatalf("expected ErrNoPullRequest for another chat, got %v", err)
+	}
+
+	if _, err := a.Mute(2, 1, fp); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, Notification{PR: pr, Text: "comment"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if n, err := w.ProcessBatch(); err != nil || n != 0 {
+		t.Fatalf("expected muted PR to send nothing, got %d (%v)", n, err)
+	}
+
+	// другой PR того же репозитория не заглушён
+	other := Notification{PR: PullRequestRef{Repo: "org/repo", Number: 6}, Text: "other"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, other); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if n, err := w.ProcessBatch(); err != nil || n != 1 {
+		t.Fatalf("expected one message for another PR, got %d (%v)", n, err)
+	}
+
+	// одобрять можно только из открытого запроса review
+	if _, err := a.Approve(2, 2, otherFP); !errors.Is(err, ErrNotReviewRequest) {
+		t.Fatalf("expected ErrNotReviewRequest for a plain notification, got %v", err)
+	}
+	if _, err := messages.MarkSentMessageEdited(2, 1, time.Now()); err != nil {
+		t.Fatalf("MarkSentMessageEdited: %v", err)
+	}
+	if _, err := a.Approve(2, 1, fp); !errors.Is(err, ErrNotReviewRequest) {
+		t.Fatalf("expected ErrNotReviewRequest for a struck-through request, got %v", err)
+	}
+}
+
+func TestPullRequestActions_IssueGetsNoPRButtons(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, memory.NewMuteRepo(), NewOutboxSender(outbox), Config{})
+
+	issue := PullRequestRef{Repo: "org/repo", Number: 9, URL: "https://github.com/org/repo/issues/9"}
+	n := Notification{Kind: EventIssueAssigned, PR: issue, Text: "issue", Issue: true}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	sender := &buttonSender{}
+	w := NewOutboxWorker(outbox, memory.NewMessageRepo(), sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	// только ссылка на issue: «заглушить» и «одобрить» относятся к PR
+	if len(sender.buttons) != 1 || len(sender.buttons[0]) != 1 || sender.buttons[0][0].URL != issue.URL {
+		t.Fatalf("expected only the link button, got %+v", sender.buttons)
+	}
+}
+
+func TestGitHubActions_MissingScopeEndToEnd(t *testing.T) {
+	// GitHub с токеном read:user (scope по умолчанию) отказывает любой записи
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "read:user")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Resource not accessible by integration"}`))
+	}))
+	t.Cleanup(srv.Close)
+	gh := github.NewClient(github.Config{APIURL: srv.URL}, nil)
+
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	messages := memory.NewMessageRepo()
+	mutes := memory.NewMuteRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, mutes, NewOutboxSender(outbox), Config{})
+
+	pr := PullRequestRef{Repo: "org/repo", Number: 5, URL: "https://github.com/org/repo/pull/5"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, Notification{Kind: EventReviewRequested, PR: pr, Text: "review"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	comment := Notification{Kind: EventReviewComment, PR: pr, Text: "comment", CommentID: 77}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, comment); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	sender := &buttonSender{}
+	w := NewOutboxWorker(outbox, messages, sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	creds := memory.NewCredentialsRepo()
+	if err := creds.SaveCredentials(repository.GitHubCredentials{TelegramID: 2, AccessToken: "tok"}); err != nil {
+		t.Fatalf("SaveCredentials: %v", err)
+	}
+	if _, err := NewPullRequestActions(messages, creds, mutes, gh).Approve(2, 1, prFingerprint("org/repo", 5)); !errors.Is(err, ErrNoGitHubScope) {
+		t.Fatalf("Approve: expected ErrNoGitHubScope, got %v", err)
+	}
+	if _, err := NewReplier(messages, creds, gh).Reply(2, 2, "fixed"); !errors.Is(err, ErrNoGitHubScope) {
+		t.Fatalf("Reply: expected ErrNoGitHubScope, got %v", err)
+	}
+
+	// повторный /link запросит тот же scope, поэтому текст отправляет к настройке бота
+	for _, key := range []string{"action.no_scope", "reply.no_scope"} {
+		if text := svc.Text(2, key, nil); !strings.Contains(text, "github.oauth.scope") {
+			t.Fatalf("%s must point to github.oauth.scope, got %q", key, text)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// Пометки (ключи i18n), которые дописываются к устаревшим сообщениям.
+const (
+	NoteMerged   = "note.merged"
+	NoteClosed   = "note.closed"
+	NoteReviewed = "note.reviewed"
+)
+
+// OpenPullRequestKinds — сообщения, которые теряют смысл, когда PR влит или закрыт.
+var OpenPullRequestKinds = []EventKind{EventAssigned, EventReviewRequested, EventReviewReminder, EventReadyForReview, EventCIFailed}
+
+// ReviewRequestKinds — сообщения, которые теряют смысл, когда ревьюер оставил review.
+var ReviewRequestKinds = []EventKind{EventReviewRequested, EventReviewReminder}
+
+// MessageEditor правит уже отправленные сообщения.
+type MessageEditor interface {
+	// StrikeMessage зачёркивает text сообщения, дописывает под ним note и убирает кнопки:
+	// действия под устаревшим сообщением уже не нужны.
+	StrikeMessage(chatID int64, messageID int, text, note string) error
+}
+
+// Annotator помечает устаревшие уведомления вместо того, чтобы оставлять их как есть.
+// Сообщениям, которые ещё ждут в outbox, пометка запоминается, и воркер зачёркивает их
+// сразу после отправки.
+type Annotator struct {
+	messages repository.MessageRepository
+	outbox   repository.OutboxRepository
+	users    repository.UserRepository
+	editor   MessageEditor
+	texts    Translator
+	now      func() time.Time
+}
+
+// NewAnnotator создаёт сервис пометок. outbox может быть nil — тогда неотправленные сообщения
+// не помечаются; texts может быть nil — тогда пометки на языке по умолчанию.
+func NewAnnotator(
+	messages repository.MessageRepository,
+	outbox repository.OutboxRepository,
+	users repository.UserRepository,
+	editor MessageEditor,
+	texts Translator,
+) *Annotator {
+	return &Annotator{messages: messages, outbox: outbox, users: users, editor: editor, texts: translatorOrDefault(texts), now: time.Now}
+}
+
+// AnnotatePullRequest помечает сообщения kinds о PR во всех чатах.
+func (a *Annotator) AnnotatePullRequest(pr PullRequestRef, note string, kinds ...EventKind) error {
+	return a.annotate(pr, note, kinds, nil)
+}
+
+// AnnotateForUser помечает сообщения kinds о PR только в чатах пользователя u.
+func (a *Annotator) AnnotateForUser(u GitHubUser, pr PullRequestRef, note string, kinds ...EventKind) error {
+	bindings, err := a.users.GetByGitHubUser(u.ID, u.Login)
+	if err != nil {
+		return fmt.Errorf("get bindings for %s: %w", u.Login, err)
+	}
+	if len(bindings) == 0 {
+		return nil
+	}
+	chats := make(map[int64]bool, len(bindings))
+	for _, b := range bindings {
+		chats[b.TelegramID] = true
+	}
+	return a.annotate(pr, note, kinds, chats)
+}
+
+// annotate правит сообщения; chats == nil — во всех чатах.
+func (a *Annotator) annotate(pr PullRequestRef, note string, kinds []EventKind, chats map[int64]bool) error {
+	want := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		want[string(k)] = true
+	}
+
+	// сначала очередь, потом отправленные: воркер сохраняет отправленное сообщение раньше,
+	// чем перечитывает пометку, так что сообщение не проскочит мимо обоих списков
+	var errs []error
+	if err := a.annotatePending(pr, note, want, chats); err != nil {
+		errs = append(errs, err)
+	}
+
+	sent, err := a.messages.ListSentMessagesByPR(pr.Repo, pr.Number)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("list sent messages: %w", err))
+		return errors.Join(errs...)
+	}
+
+	for _, m := range sent {
+		if !want[m.Ref.Kind] || (chats != nil && !chats[m.ChatID]) || m.Text == "" {
+			continue
+		}
+		// отметка ставится до правки: параллельная пометка или воркер outbox
+		// могли уже взять это сообщение
+		at := a.now()
+		marked, err := a.messages.MarkSentMessageEdited(m.ChatID, m.MessageID, at)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mark message %d in %d edited: %w", m.MessageID, m.ChatID, err))
+			continue
+		}
+		if !marked {
+			continue
+		}
+		text := a.texts.Text(m.ChatID, note, nil)
+		if err := a.editor.StrikeMessage(m.ChatID, m.MessageID, strikeText(m.Text, text), text); err != nil {
+			errs = append(errs, fmt.Errorf("edit message %d in %d: %w", m.MessageID, m.ChatID, err))
+			// правка не удалась — следующая пометка попробует снова
+			if err := a.messages.UnmarkSentMessageEdited(m.ChatID, m.MessageID, at); err != nil {
+				errs = append(errs, fmt.Errorf("unmark message %d in %d: %w", m.MessageID, m.ChatID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// annotatePending запоминает пометку у сообщений, которые ещё ждут отправки.
+func (a *Annotator) annotatePending(pr PullRequestRef, note string, want map[string]bool, chats map[int64]bool) error {
+	if a.outbox == nil {
+		return nil
+	}
+	pending, err := a.outbox.ListPendingByPR(pr.Repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("list pending messages: %w", err)
+	}
+
+	var errs []error
+	for _, m := range pending {
+		if !want[m.Ref.Kind] || (chats != nil && !chats[m.ChatID]) || m.Note != "" {
+			continue
+		}
+		// уже отправленное сообщение найдётся среди отправленных
+		err := a.outbox.SetNote(m.ID, a.texts.Text(m.ChatID, note, nil))
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("note outbox message %d: %w", m.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// strikeText обрезает зачёркиваемый текст так, чтобы вместе с пометкой он уместился
+// в одно сообщение.
+func strikeText(text, note string) string {
+	limit := maxMessageLen - utf8.RuneCountInString(note) - 2
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	if limit <= 1 {
+		return ""
+	}
+	return string([]rune(text)[:limit-1]) + "…"
+}
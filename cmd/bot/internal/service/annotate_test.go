@@ -0,0 +1,207 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type editorMock struct {
+	edits []string
+}
+
+func (m *editorMock) StrikeMessage(chatID int64, messageID int, text, note string) error {
+	m.edits = append(m.edits, fmt.Sprintf("%d/%d %s | %s", chatID, messageID, text, note))
+	return nil
+}
+
+// editingSender отправляет и правит сообщения, как Telegram-отправитель.
+type editingSender struct {
+	idSender
+	editorMock
+}
+
+func TestAnnotator_AnnotatesOutdatedMessages(t *testing.T) {
+	users := memory.NewUserRepo()
+	for tgID, login := range map[int64]string{1: "author", 2: "reviewer"} {
+		if err := users.SaveBinding(repository.UserBinding{TelegramID: tgID, GitHubLogin: login}); err != nil {
+			t.Fatalf("SaveBinding: %v", err)
+		}
+	}
+	messages := memory.NewMessageRepo()
+	save := func(chatID int64, msgID int, kind EventKind) {
+		t.Helper()
+		m := repository.SentMessage{
+			ChatID:    chatID,
+			MessageID: msgID,
+			Ref:       repository.MessageRef{Repo: "org/repo", Number: 5, Kind: string(kind)},
+			Text:      string(kind),
+		}
+		if err := messages.SaveSentMessage(m); err != nil {
+			t.Fatalf("SaveSentMessage: %v", err)
+		}
+	}
+	save(1, 10, EventAssigned)
+	save(1, 11, EventApproved)
+	save(2, 20, EventReviewRequested)
+
+	editor := &editorMock{}
+	a := NewAnnotator(messages, nil, users, editor, nil)
+	pr := PullRequestRef{Repo: "org/repo", Number: 5}
+
+	if err := a.AnnotateForUser(GitHubUser{Login: "reviewer"}, pr, NoteReviewed, ReviewRequestKinds...); err != nil {
+		t.Fatalf("AnnotateForUser: %v", err)
+	}
+	if len(editor.edits) != 1 || editor.edits[0] != "2/20 review_requested | ☑️ Review уже оставлен" {
+		t.Fatalf("unexpected edits %v", editor.edits)
+	}
+
+	// уже поправленное сообщение второй раз не трогаем, approved не устаревает
+	if err := a.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(editor.edits) != 2 || editor.edits[1] != "1/10 assigned | ✅ PR влит" {
+		t.Fatalf("unexpected edits %v", editor.edits)
+	}
+}
+
+func TestAnnotator_TruncatesLongText(t *testing.T) {
+	messages := memory.NewMessageRepo()
+	long := strings.Repeat("я", maxMessageLen)
+	m := repository.SentMessage{
+		ChatID:    1,
+		MessageID: 10,
+		Ref:       repository.MessageRef{Repo: "org/repo", Number: 5, Kind: string(EventAssigned)},
+		Text:      long,
+	}
+	if err := messages.SaveSentMessage(m); err != nil {
+		t.Fatalf("SaveSentMessage: %v", err)
+	}
+
+	editor := &editorMock{}
+	a := NewAnnotator(messages, nil, memory.NewUserRepo(), editor, nil)
+	if err := a.AnnotatePullRequest(PullRequestRef{Repo: "org/repo", Number: 5}, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(editor.edits) != 1 {
+		t.Fatalf("unexpected edits %v", editor.edits)
+	}
+	text, note, _ := strings.Cut(strings.TrimPrefix(editor.edits[0], "1/10 "), " | ")
+	if got := utf8.RuneCountInString(text) + 2 + utf8.RuneCountInString(note); got > maxMessageLen {
+		t.Fatalf("edited message has %d characters, limit %d", got, maxMessageLen)
+	}
+	if !strings.HasSuffix(text, "…") || note != "✅ PR влит" {
+		t.Fatalf("expected a truncated text and the note, got note %q", note)
+	}
+}
+
+func TestAnnotator_StrikesMessagesStillInOutbox(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	messages := memory.NewMessageRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, NewOutboxSender(outbox), Config{})
+
+	pr := PullRequestRef{Repo: "org/repo", Number: 5}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, Notification{Kind: EventReviewRequested, PR: pr, Text: "review please"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	// PR влили, пока запрос review ждал в очереди
+	sender := &editingSender{}
+	a := NewAnnotator(messages, outbox, users, sender, nil)
+	if err := a.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(sender.edits) != 0 {
+		t.Fatalf("nothing is sent yet, got edits %v", sender.edits)
+	}
+
+	w := NewOutboxWorker(outbox, messages, sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if len(sender.sent[2]) != 1 || len(sender.edits) != 1 || sender.edits[0] != "2/1 review please | ✅ PR влит" {
+		t.Fatalf("expected the sent message to be struck, got sent %v, edits %v", sender.sent[2], sender.edits)
+	}
+
+	// второй раз не правим
+	if err := a.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(sender.edits) != 1 {
+		t.Fatalf("message edited twice: %v", sender.edits)
+	}
+}
+
+// staleMessages отдаёт список отправленных, прочитанный до того, как сообщение зачеркнули.
+type staleMessages struct {
+	*memory.MessageRepo
+	list []repository.SentMessage
+}
+
+func (r staleMessages) ListSentMessagesByPR(string, int) ([]repository.SentMessage, error) {
+	return r.list, nil
+}
+
+func TestAnnotator_StrikesMessageOnce(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	messages := memory.NewMessageRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, NewOutboxSender(outbox), Config{})
+
+	pr := PullRequestRef{Repo: "org/repo", Number: 5}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "reviewer"}, Notification{Kind: EventReviewRequested, PR: pr, Text: "review please"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	sender := &editingSender{}
+	a := NewAnnotator(messages, outbox, users, sender, nil)
+	// пометка попала в outbox, и воркер зачеркнёт сообщение после отправки
+	if err := a.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	pending, err := outbox.ListPendingByPR(pr.Repo, pr.Number)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("expected one pending message, got %+v (%v)", pending, err)
+	}
+
+	// отправка уже сохранена, и параллельная пометка зачеркнула сообщение раньше воркера
+	sent := repository.SentMessage{ChatID: 2, MessageID: 1, Ref: *pending[0].Ref, Text: pending[0].Text, SentAt: time.Now()}
+	if err := messages.SaveSentMessage(sent); err != nil {
+		t.Fatalf("SaveSentMessage: %v", err)
+	}
+	list, err := messages.ListSentMessagesByPR(pr.Repo, pr.Number)
+	if err != nil {
+		t.Fatalf("ListSentMessagesByPR: %v", err)
+	}
+	if err := a.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(sender.edits) != 1 {
+		t.Fatalf("expected one edit, got %v", sender.edits)
+	}
+
+	// ни воркер, ни пометка, прочитавшая список до правки, второй раз не зачёркивают
+	w := NewOutboxWorker(outbox, messages, sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.strikeOutdated(pending[0], 1)
+	stale := NewAnnotator(staleMessages{MessageRepo: messages, list: list}, nil, users, sender, nil)
+	if err := stale.AnnotatePullRequest(pr, NoteMerged, OpenPullRequestKinds...); err != nil {
+		t.Fatalf("AnnotatePullRequest: %v", err)
+	}
+	if len(sender.edits) != 1 {
+		t.Fatalf("message struck twice: %v", sender.edits)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// сколько фрагментов комментариев показывать в склеенном сообщении
+const batchSnippets = 3
+
+type commentKey struct {
+	tgID   int64
+	repo   string
+	number int
+	// late — комментарии автора review, пришедшие уже после него: webhook обрабатываются
+	// параллельно, и review может обогнать свои комментарии
+	late bool
+}
+
+// commentBatch — комментарии к коду, ждущие отправки одним сообщением.
+type commentBatch struct {
+	st       repository.UserSettings
+	comments []Notification
+	late     bool
+	flushAt  time.Time
+}
+
+// reviewKey — review одного автора по PR у получателя.
+type reviewKey struct {
+	tgID   int64
+	repo   string
+	number int
+	actor  string
+}
+
+// commentBatcher склеивает review comments, пришедшие одному получателю по одному PR
+// за окно window. Пачки живут в памяти: при рестарте неотправленные теряются.
+type commentBatcher struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	batches  map[commentKey]*commentBatch
+	reviewed map[reviewKey]time.Time // до какого момента комментарии автора review считаются опоздавшими
+}
+
+func newCommentBatcher(window time.Duration) *commentBatcher {
+	return &commentBatcher{
+		window:   window,
+		batches:  make(map[commentKey]*commentBatch),
+		reviewed: make(map[reviewKey]time.Time),
+	}
+}
+
+func batchKey(tgID int64, pr PullRequestRef) commentKey {
+	return commentKey{tgID: tgID, repo: strings.ToLower(pr.Repo), number: pr.Number}
+}
+
+func reviewKeyOf(tgID int64, n Notification) reviewKey {
+	return reviewKey{tgID: tgID, repo: strings.ToLower(n.PR.Repo), number: n.PR.Number, actor: normalizeLogin(n.Actor.Login)}
+}
+
+// add кладёт комментарий в пачку. Комментарий автора review, о котором уже сообщили,
+// в него не попал — такие собираются в отдельную пачку-дополнение к review.
+func (b *commentBatcher) add(st repository.UserSettings, n Notification, now time.Time) {
+	key := batchKey(st.TelegramID, n.PR)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rk := reviewKeyOf(st.TelegramID, n)
+	if until, ok := b.reviewed[rk]; ok {
+		if now.Before(until) {
+			key.late = true
+		} else {
+			delete(b.reviewed, rk)
+		}
+	}
+
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &commentBatch{st: st, late: key.late, flushAt: now.Add(b.window)}
+		b.batches[key] = batch
+	}
+	batch.comments = append(batch.comments, n)
+}
+
+// takeForReview забирает из пачки комментарии автора review n: review заменяет их,
+// и фрагменты дописываются к нему. Комментарии других людей остаются в пачке.
+func (b *commentBatcher) takeForReview(tgID int64, n Notification, now time.Time) []string {
+	key := batchKey(tgID, n.PR)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reviewed[reviewKeyOf(tgID, n)] = now.Add(b.window)
+
+	batch := b.batches[key]
+	if batch == nil {
+		return nil
+	}
+	var (
+		snippets []string
+		rest     []Notification
+	)
+	for _, c := range batch.comments {
+		if c.Actor.Same(n.Actor) {
+			snippets = append(snippets, commentSnippet(c))
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	if len(rest) == 0 {
+		delete(b.batches, key)
+	} else {
+		batch.comments = rest
+	}
+	return snippets
+}
+
+// due забирает пачки, окно которых закончилось к моменту now.
+func (b *commentBatcher) due(now time.Time) []*commentBatch {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out []*commentBatch
+	for key, batch := range b.batches {
+		if !now.Before(batch.flushAt) {
+			out = append(out, batch)
+			delete(b.batches, key)
+		}
+	}
+	for key, until := range b.reviewed {
+		if !now.Before(until) {
+			delete(b.reviewed, key)
+		}
+	}
+	return out
+}
+
+// FlushComments отправляет пачки комментариев к коду, окно которых закончилось.
+func (s *Notifier) FlushComments(now time.Time) error {
+	if s.comments == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, batch := range s.comments.due(now) {
+		if err := s.deliver(batch.st, s.batchNotification(batch)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// batchNotification — одно сообщение на пачку; единственный комментарий уходит как есть,
+// чтобы на него можно было ответить реплаем. Несколько опоздавших комментариев review
+// уходят дополнением к нему.
+func (s *Notifier) batchNotification(b *commentBatch) Notification {
+	first := b.comments[0]
+	if len(b.comments) == 1 {
+		return first
+	}
+	snippets := make([]string, 0, len(b.comments))
+	for _, c := range b.comments {
+		snippets = append(snippets, commentSnippet(c))
+	}
+	n := first
+	n.CommentID = 0
+	n.Key = "notify.comments_batch"
+	if b.late {
+		n.Key = "notify.review_late_comments"
+	}
+	n.Args = i18n.Args{
+		"Count":    len(snippets),
+		"Title":    n.PR.Title,
+		"URL":      n.PR.URL,
+		"Reviewer": n.Actor.Login,
+		"Snippets": s.formatSnippets(b.st, snippets),
+	}
+	return n
+}
+
+// reviewWithComments — хвост review с фрагментами комментариев, пришедших до него.
+func (s *Notifier) reviewWithComments(st repository.UserSettings, snippets []string) string {
+	return s.text(st, "notify.review_with_comments", i18n.Args{
+		"Count":    len(snippets),
+		"Snippets": s.formatSnippets(st, snippets),
+	})
+}
+
+func commentSnippet(n Notification) string {
+	if n.Snippet != "" {
+		return n.Snippet
+	}
+	return n.Text
+}
+
+func (s *Notifier) formatSnippets(st repository.UserSettings, snippets []string) string {
+	shown := snippets
+	if len(shown) > batchSnippets {
+		shown = shown[:batchSnippets]
+	}
+	lines := make([]string, 0, len(shown)+1)
+	for _, snippet := range shown {
+		lines = append(lines, "• "+snippet)
+	}
+	if rest := len(snippets) - len(shown); rest > 0 {
+		lines = append(lines, s.text(st, "notify.more", i18n.Args{"Count": rest}))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+)
+
+func reviewComment(body string) Notification {
+	return Notification{
+		Kind:    EventReviewComment,
+		Actor:   GitHubUser{Login: "reviewer"},
+		PR:      PullRequestRef{Repo: "org/repo", Number: 5, Title: "Fix", URL: "https://github.com/org/repo/pull/5"},
+		Text:    "Новый комментарий к вашему PR: " + body,
+		Snippet: body,
+	}
+}
+
+func TestNotifier_CoalescesReviewComments(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{CommentWindow: 30 * time.Second})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	for _, body := range []string{"one", "two", "three", "four"} {
+		if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, reviewComment(body)); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("expected comments to wait for the window, got %v", sender.sent[1])
+	}
+
+	if err := svc.FlushComments(now.Add(10 * time.Second)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("expected nothing before the window ends, got %v", sender.sent[1])
+	}
+
+	if err := svc.FlushComments(now.Add(30 * time.Second)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected one coalesced message, got %v", sender.sent[1])
+	}
+	msg := sender.sent[1][0]
+	if !strings.Contains(msg, "(4)") || !strings.Contains(msg, "• one") || !strings.Contains(msg, "• three") ||
+		strings.Contains(msg, "• four") || !strings.Contains(msg, "и ещё 1") {
+		t.Fatalf("unexpected coalesced message %q", msg)
+	}
+}
+
+func TestNotifier_SingleBatchedCommentIsSentAsIs(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{CommentWindow: 30 * time.Second})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	n := reviewComment("only")
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if err := svc.FlushComments(now.Add(time.Minute)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != n.Text {
+		t.Fatalf("unexpected messages %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_ReviewReplacesCommentBatch(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{CommentWindow: 30 * time.Second})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	author := GitHubUser{Login: "author"}
+	for _, body := range []string{"one", "two"} {
+		if err := svc.NotifyAssignee(author, reviewComment(body)); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+
+	review := Notification{
+		Kind:  EventChangesRequested,
+		Actor: GitHubUser{Login: "reviewer"},
+		PR:    PullRequestRef{Repo: "org/repo", Number: 5},
+		Text:  "По вашему PR запрошены изменения",
+	}
+	if err := svc.NotifyAssignee(author, review); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected only the review message, got %v", sender.sent[1])
+	}
+	msg := sender.sent[1][0]
+	if !strings.HasPrefix(msg, review.Text) || !strings.Contains(msg, "Комментарии к коду (2)") {
+		t.Fatalf("unexpected review message %q", msg)
+	}
+
+	// комментарии того же review, обработанные после него, уходят дополнением к review
+	for _, body := range []string{"late one", "late two"} {
+		if err := svc.NotifyAssignee(author, reviewComment(body)); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+	if err := svc.FlushComments(now.Add(time.Minute)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("expected late comments in a follow-up, got %v", sender.sent[1])
+	}
+	late := sender.sent[1][1]
+	if !strings.Contains(late, "из review reviewer (2)") || !strings.Contains(late, "• late one") || !strings.Contains(late, "• late two") {
+		t.Fatalf("unexpected follow-up %q", late)
+	}
+}
+
+func TestNotifier_ReviewTakesOnlyItsAuthorsComments(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{CommentWindow: 30 * time.Second})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	author := GitHubUser{Login: "author"}
+	other := reviewComment("from carol")
+	other.Actor = GitHubUser{Login: "carol"}
+	for _, n := range []Notification{reviewComment("mine"), other} {
+		if err := svc.NotifyAssignee(author, n); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+
+	review := Notification{
+		Kind:  EventApproved,
+		Actor: GitHubUser{Login: "reviewer"},
+		PR:    PullRequestRef{Repo: "org/repo", Number: 5},
+		Text:  "PR одобрен",
+	}
+	if err := svc.NotifyAssignee(author, review); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if err := svc.FlushComments(now.Add(time.Minute)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("expected the review and the other comment, got %v", sender.sent[1])
+	}
+	if msg := sender.sent[1][0]; !strings.Contains(msg, "• mine") || strings.Contains(msg, "from carol") {
+		t.Fatalf("review must list only its author's comments, got %q", msg)
+	}
+	if sender.sent[1][1] != other.Text {
+		t.Fatalf("expected the other comment as is, got %q", sender.sent[1][1])
+	}
+}
+
+func TestNotifier_CommentBatchTextDependsOnRole(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{
+		CommentWindow: 30 * time.Second,
+		Recipients:    RecipientPolicy{Author: true, Reviewers: true},
+	})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	p := Participants{Author: GitHubUser{Login: "author"}, Reviewers: []GitHubUser{{Login: "reviewer"}}}
+	for _, body := range []string{"one", "two"} {
+		n := reviewComment(body)
+		n.Actor = GitHubUser{Login: "carol"}
+		n.Text = ""
+		n.Key = "notify.review_comment"
+		n.Args = i18n.Args{"Title": n.PR.Title, "URL": n.PR.URL, "Comment": body}
+		if err := svc.NotifyParticipants(p, n); err != nil {
+			t.Fatalf("NotifyParticipants: %v", err)
+		}
+	}
+	if err := svc.FlushComments(now.Add(time.Minute)); err != nil {
+		t.Fatalf("FlushComments: %v", err)
+	}
+
+	if len(sender.sent[1]) != 1 || !strings.HasPrefix(sender.sent[1][0], "Новые комментарии к вашему PR (2)") {
+		t.Fatalf("unexpected author message %v", sender.sent[1])
+	}
+	if len(sender.sent[2]) != 1 || !strings.HasPrefix(sender.sent[2][0], "Новые комментарии к PR (2)") {
+		t.Fatalf("unexpected reviewer message %v", sender.sent[2])
+	}
+}
+
+func TestNotifier_ApprovalTextDependsOnRole(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{Recipients: RecipientPolicy{Author: true, Reviewers: true}})
+
+	p := Participants{Author: GitHubUser{Login: "author"}, Reviewers: []GitHubUser{{Login: "reviewer"}}}
+	n := Notification{
+		Kind:  EventApproved,
+		Actor: GitHubUser{Login: "carol"},
+		PR:    PullRequestRef{Repo: "org/repo", Number: 5, Title: "Fix", URL: "https://github.com/org/repo/pull/5"},
+		Key:   "notify.approved",
+		Args:  i18n.Args{"Title": "Fix", "URL": "https://github.com/org/repo/pull/5"},
+	}
+	if err := svc.NotifyParticipants(p, n); err != nil {
+		t.Fatalf("NotifyParticipants: %v", err)
+	}
+
+	if len(sender.sent[1]) != 1 || !strings.HasPrefix(sender.sent[1][0], "Ваш PR одобрен") {
+		t.Fatalf("unexpected author message %v", sender.sent[1])
+	}
+	// другой ревьюер видит чужой PR, а не «ваш»
+	if len(sender.sent[2]) != 1 || !strings.HasPrefix(sender.sent[2][0], "PR одобрен") {
+		t.Fatalf("unexpected reviewer message %v", sender.sent[2])
+	}
+}
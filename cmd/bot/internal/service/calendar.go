@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkCalendar — рабочие дни и часы, по которым считается время ожидания review.
+// Пустой календарь считает рабочим всё время.
+type WorkCalendar struct {
+	Location *time.Location
+	Days     map[time.Weekday]bool // пусто — все дни рабочие
+	Start    int                   // начало рабочего дня, минуты от полуночи
+	End      int                   // конец рабочего дня; Start == End — весь день
+	Holidays map[string]bool       // даты "2006-01-02" в Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// NewWorkCalendar собирает календарь из настроек: часовой пояс, дни недели ("mon".."sun"),
+// рабочие часы "10:00-19:00" (пусто — весь день) и праздники "2006-01-02".
+func NewWorkCalendar(tz string, days []string, hours string, holidays []string) (WorkCalendar, error) {
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return WorkCalendar{}, err
+	}
+	c := WorkCalendar{Location: loc, Days: make(map[time.Weekday]bool), Holidays: make(map[string]bool)}
+
+	for _, d := range days {
+		name := strings.ToLower(strings.TrimSpace(d))
+		if len(name) > 3 {
+			name = name[:3] // "monday" -> "mon"
+		}
+		wd, ok := weekdays[name]
+		if !ok {
+			return WorkCalendar{}, fmt.Errorf("unknown weekday %q", d)
+		}
+		c.Days[wd] = true
+	}
+
+	if hours = strings.TrimSpace(hours); hours != "" {
+		from, to, ok := strings.Cut(hours, "-")
+		if !ok {
+			return WorkCalendar{}, fmt.Errorf("work hours %q: expected HH:MM-HH:MM", hours)
+		}
+		if c.Start, err = ParseClock(from); err != nil {
+			return WorkCalendar{}, err
+		}
+		if c.End, err = ParseClock(to); err != nil {
+			return WorkCalendar{}, err
+		}
+		if c.End < c.Start {
+			return WorkCalendar{}, fmt.Errorf("work hours %q: end before start", hours)
+		}
+	}
+
+	for _, h := range holidays {
+		day, err := time.Parse("2006-01-02", strings.TrimSpace(h))
+		if err != nil {
+			return WorkCalendar{}, fmt.Errorf("bad holiday %q: expected YYYY-MM-DD", h)
+		}
+		c.Holidays[day.Format("2006-01-02")] = true
+	}
+	return c, nil
+}
+
+// IsWorkingTime сообщает, рабочее ли сейчас время.
+func (c WorkCalendar) IsWorkingTime(t time.Time) bool {
+	from, to, ok := c.workingWindow(t)
+	return ok && !t.Before(from) && t.Before(to)
+}
+
+// WorkingDuration считает рабочее время между from и to.
+func (c WorkCalendar) WorkingDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	var total time.Duration
+	// не больше года назад: дальше напоминать уже бессмысленно
+	for day, i := from, 0; !day.After(to) && i <= 366; day, i = c.nextDay(day), i+1 {
+		start, end, ok := c.workingWindow(day)
+		if !ok {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// workingWindow возвращает рабочий интервал дня, в который попадает t.
+func (c WorkCalendar) workingWindow(t time.Time) (time.Time, time.Time, bool) {
+	local := t.In(c.location())
+	at := func(minutes int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, c.location())
+	}
+
+	if len(c.Days) > 0 && !c.Days[local.Weekday()] {
+		return time.Time{}, time.Time{}, false
+	}
+	if c.Holidays[local.Format("2006-01-02")] {
+		return time.Time{}, time.Time{}, false
+	}
+	if c.Start == c.End {
+		return at(0), at(24 * 60), true
+	}
+	return at(c.Start), at(c.End), true
+}
+
+func (c WorkCalendar) nextDay(t time.Time) time.Time {
+	local := t.In(c.location())
+	return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, c.location())
+}
+
+func (c WorkCalendar) location() *time.Location {
+	if c.Location == nil {
+		return time.UTC
+	}
+	return c.Location
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkCalendar_WorkingDuration(t *testing.T) {
+	cal, err := NewWorkCalendar("UTC", []string{"mon", "tue", "wed", "thu", "friday"}, "10:00-19:00", []string{"2024-03-05"})
+	if err != nil {
+		t.Fatalf("NewWorkCalendar: %v", err)
+	}
+
+	// 2024-03-01 — пятница, 2024-03-05 — вторник (праздник)
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     time.Duration
+	}{
+		{
+			name: "same day",
+			from: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC),
+			want: 4*time.Hour + 30*time.Minute,
+		},
+		{
+			name: "before and after work hours",
+			from: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC),
+			want: 9 * time.Hour,
+		},
+		{
+			name: "over weekend",
+			from: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC),
+			want: 2 * time.Hour,
+		},
+		{
+			name: "over holiday",
+			from: time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 3, 6, 11, 0, 0, 0, time.UTC),
+			want: 2 * time.Hour,
+		},
+		{
+			name: "reversed",
+			from: time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			to:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cal.WorkingDuration(tt.from, tt.to); got != tt.want {
+				t.Fatalf("WorkingDuration = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if !cal.IsWorkingTime(time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("monday noon must be working time")
+	}
+	if cal.IsWorkingTime(time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("holiday must not be working time")
+	}
+	if cal.IsWorkingTime(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Fatalf("saturday must not be working time")
+	}
+}
+
+func TestWorkCalendar_EmptyIsAlwaysWorking(t *testing.T) {
+	var cal WorkCalendar
+	from := time.Date(2024, 3, 2, 22, 0, 0, 0, time.UTC)
+	if got := cal.WorkingDuration(from, from.Add(5*time.Hour)); got != 5*time.Hour {
+		t.Fatalf("WorkingDuration = %s, want 5h", got)
+	}
+	if !cal.IsWorkingTime(from) {
+		t.Fatalf("empty calendar must treat any time as working")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// CIFailure — упавшая проверка из check_suite, check_run или status.
+type CIFailure struct {
+	Repo    string // owner/name
+	HeadSHA string
+	Name    string // имя проверки или контекст status
+	URL     string // ссылка на проверку; пусто — ссылка на вкладку Checks PR
+	At      time.Time
+}
+
+// CIMonitor сообщает авторам PR об упавшем CI. Об одной проверке head-коммита пишет
+// не чаще раза в throttle: перезапуски той же проверки не превращаются в серию сообщений,
+// а падение другой проверки того же коммита приходит отдельно.
+type CIMonitor struct {
+	prs      repository.PullRequestRepository
+	notifier *Notifier
+	throttle time.Duration
+
+	// учёт только в памяти: после рестарта о том же падении могут написать ещё раз
+	mu       sync.Mutex
+	notified map[string]time.Time // repo@sha/проверка -> когда сообщили
+}
+
+func NewCIMonitor(prs repository.PullRequestRepository, notifier *Notifier, throttle time.Duration) *CIMonitor {
+	return &CIMonitor{prs: prs, notifier: notifier, throttle: throttle, notified: make(map[string]time.Time)}
+}
+
+// ReportCIFailure находит открытые PR с этим head-коммитом и уведомляет их авторов.
+func (c *CIMonitor) ReportCIFailure(f CIFailure) error {
+	prs, err := c.prs.ListByHeadSHA(f.Repo, f.HeadSHA)
+	if err != nil {
+		return fmt.Errorf("list pull requests by head %s: %w", f.HeadSHA, err)
+	}
+	if len(prs) == 0 {
+		return nil
+	}
+	key := ciKey(f)
+	at := f.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if !c.allow(key, at) {
+		return nil
+	}
+
+	var errs []error
+	for _, pr := range prs {
+		ref := refOf(pr)
+		link := f.URL
+		if link == "" {
+			link = strings.TrimSuffix(ref.URL, "/") + "/checks"
+		}
+		name := f.Name
+		if name == "" {
+			name = "CI"
+		}
+
+		n := Notification{
+			Kind: EventCIFailed,
+			PR:   ref,
+			Key:  "notify.ci_failed",
+			Args: i18n.Args{
+				"Check":  name,
+				"Repo":   ref.Repo,
+				"Number": ref.Number,
+				"Title":  ref.Title,
+				"URL":    link,
+			},
+			Buttons: [][]Button{{{Key: "button.open_check", URL: link}}},
+		}
+		if err := c.notifier.NotifyAssignee(GitHubUser{ID: pr.AuthorID, Login: pr.AuthorLogin}, n); err != nil {
+			errs = append(errs, fmt.Errorf("notify author of %s#%d: %w", pr.Repo, pr.Number, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		// сообщение не ушло — повторная доставка webhook должна попробовать снова
+		c.forget(key, at)
+		return err
+	}
+	return nil
+}
+
+func ciKey(f CIFailure) string {
+	return strings.ToLower(f.Repo) + "@" + f.HeadSHA + "/" + f.Name
+}
+
+// allow отмечает падение и сообщает, можно ли о нём писать. Отметка ставится сразу,
+// чтобы параллельные доставки об одной проверке не написали дважды; при ошибке её снимает forget.
+func (c *CIMonitor) allow(key string, at time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for k, t := range c.notified {
+		if at.Sub(t) >= c.throttle {
+			delete(c.notified, k)
+		}
+	}
+	if _, ok := c.notified[key]; ok {
+		return false
+	}
+	c.notified[key] = at
+	return true
+}
+
+func (c *CIMonitor) forget(key string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.notified[key]; ok && t.Equal(at) {
+		delete(c.notified, key)
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+func TestCIMonitor_NotifiesAuthorAndThrottles(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	prs := memory.NewPullRequestRepo()
+	err := prs.SavePullRequest(repository.PullRequest{
+		Repo: "org/repo", Number: 7, Title: "Fix", URL: "https://github.com/org/repo/pull/7",
+		AuthorLogin: "author", State: repository.PRStateOpen, HeadSHA: "abc",
+	})
+	if err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+
+	ci := NewCIMonitor(prs, svc, time.Hour)
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	// неизвестный коммит — никому
+	if err := ci.ReportCIFailure(CIFailure{Repo: "org/repo", HeadSHA: "zzz", Name: "build", At: t0}); err != nil {
+		t.Fatalf("ReportCIFailure: %v", err)
+	}
+	// перезапуск той же проверки — одно сообщение
+	for i := 0; i < 2; i++ {
+		if err := ci.ReportCIFailure(CIFailure{Repo: "Org/Repo", HeadSHA: "abc", Name: "build", At: t0}); err != nil {
+			t.Fatalf("ReportCIFailure: %v", err)
+		}
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected one message to author, got %v", sender.sent[1])
+	}
+
+	// другая проверка того же коммита — отдельное сообщение
+	if err := ci.ReportCIFailure(CIFailure{Repo: "org/repo", HeadSHA: "abc", Name: "lint", At: t0}); err != nil {
+		t.Fatalf("ReportCIFailure: %v", err)
+	}
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("expected a message about another check, got %v", sender.sent[1])
+	}
+
+	// после окна — снова
+	if err := ci.ReportCIFailure(CIFailure{Repo: "org/repo", HeadSHA: "abc", Name: "build", At: t0.Add(time.Hour)}); err != nil {
+		t.Fatalf("ReportCIFailure: %v", err)
+	}
+	if len(sender.sent[1]) != 3 {
+		t.Fatalf("expected repeated failure after throttle window, got %v", sender.sent[1])
+	}
+}
+
+func TestCIMonitor_FailedNotificationIsNotThrottled(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &failingSender{err: errors.New("boom")}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, sender, Config{})
+	prs := memory.NewPullRequestRepo()
+	err := prs.SavePullRequest(repository.PullRequest{
+		Repo: "org/repo", Number: 7, AuthorLogin: "author", State: repository.PRStateOpen, HeadSHA: "abc",
+	})
+	if err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+
+	ci := NewCIMonitor(prs, svc, time.Hour)
+	f := CIFailure{Repo: "org/repo", HeadSHA: "abc", Name: "build", At: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)}
+	if err := ci.ReportCIFailure(f); err == nil {
+		t.Fatalf("expected notify error")
+	}
+
+	// повторная доставка того же падения пробует снова
+	sender.err = nil
+	if err := ci.ReportCIFailure(f); err != nil {
+		t.Fatalf("ReportCIFailure: %v", err)
+	}
+	if sender.calls != 2 {
+		t.Fatalf("expected the failure to be sent again, got %d attempts", sender.calls)
+	}
+}
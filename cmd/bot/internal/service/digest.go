@@ -0,0 +1,326 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// DigestSettings — режим дайджеста пользователя.
+type DigestSettings struct {
+	Enabled  bool
+	Time     int // минуты от полуночи
+	Location *time.Location
+}
+
+func (d DigestSettings) String() string {
+	return fmt.Sprintf("%s %s", formatClock(d.Time), d.Location)
+}
+
+func (s *Notifier) DigestSettings(tgID int64) (DigestSettings, error) {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return DigestSettings{}, err
+	}
+	loc, err := loadLocation(st.Timezone)
+	if err != nil {
+		return DigestSettings{}, err
+	}
+	return DigestSettings{Enabled: st.DigestEnabled, Time: s.digestTime(st), Location: loc}, nil
+}
+
+// SetDigest включает или выключает дайджест. После выключения накопленное
+// уходит при следующем запуске SendDigests.
+func (s *Notifier) SetDigest(tgID int64, enabled bool) error {
+	if enabled && s.digest == nil {
+		return fmt.Errorf("digest is not available")
+	}
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return err
+	}
+	st.DigestEnabled = enabled
+	return s.settings.SaveSettings(st)
+}
+
+// SetDigestTime задаёт время дайджеста в минутах от полуночи в часовом поясе пользователя.
+func (s *Notifier) SetDigestTime(tgID int64, minutes int) error {
+	if minutes < 0 || minutes >= 24*60 {
+		return fmt.Errorf("digest time out of range")
+	}
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return err
+	}
+	st.DigestTime = &minutes
+	return s.settings.SaveSettings(st)
+}
+
+// SendDigests отправляет дайджесты пользователям, у которых наступило их время.
+// Пользователям, выключившим дайджест, накопленное уходит сразу. Дайджест приходит и без
+// накопленных событий, если PR всё ещё ждут review пользователя.
+func (s *Notifier) SendDigests(now time.Time) error {
+	if s.digest == nil {
+		return nil
+	}
+
+	withEvents, err := s.digest.DigestRecipients()
+	if err != nil {
+		return fmt.Errorf("get digest recipients: %w", err)
+	}
+	enabled, err := s.settings.DigestUsers()
+	if err != nil {
+		return fmt.Errorf("get digest users: %w", err)
+	}
+
+	var (
+		errs  []error
+		users []int64
+		seen  = make(map[int64]bool, len(withEvents)+len(enabled))
+	)
+	for _, tgID := range append(withEvents, enabled...) {
+		if !seen[tgID] {
+			seen[tgID] = true
+			users = append(users, tgID)
+		}
+	}
+	for _, tgID := range users {
+		if err := s.sendDigest(tgID, now); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Notifier) sendDigest(tgID int64, now time.Time) error {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return fmt.Errorf("get settings for %d: %w", tgID, err)
+	}
+
+	cutoff := now
+	// withPending — слот дайджеста ещё не разослан: запросы review показываются и без событий
+	withPending := false
+	if st.DigestEnabled {
+		loc, err := loadLocation(st.Timezone)
+		if err != nil {
+			return fmt.Errorf("digest for %d: %w", tgID, err)
+		}
+		cutoff = lastDigestSlot(now, s.digestTime(st), loc)
+
+		last, err := s.digest.LastDigestSlot(tgID)
+		if err != nil {
+			return fmt.Errorf("get last digest of %d: %w", tgID, err)
+		}
+		if !last.Before(cutoff) {
+			return nil
+		}
+		// дайджест только что включили: начинаем со следующего слота, а не шлём сразу
+		withPending = !last.IsZero()
+	}
+
+	events, err := s.digest.PendingDigestEvents(tgID, cutoff)
+	if err != nil {
+		return fmt.Errorf("get digest events for %d: %w", tgID, err)
+	}
+	if len(events) == 0 && !withPending {
+		return s.markDigestSent(st, tgID, cutoff)
+	}
+
+	// запросы review берутся из состояния PR: к утру часть уже сделана или снята
+	var pending []ReviewRequest
+	shown := events
+	if s.pending != nil {
+		pending, err = s.pending.PendingReviews(tgID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("get pending reviews for %d: %w", tgID, err)
+		}
+		shown = make([]repository.DigestEvent, 0, len(events))
+		for _, e := range events {
+			if e.Kind != string(EventReviewRequested) {
+				shown = append(shown, e)
+			}
+		}
+	}
+
+	text := func(key string, args i18n.Args) string { return s.text(st, key, args) }
+	for _, msg := range formatDigest(shown, pending, text) {
+		if err := s.sender.SendMessage(tgID, msg); err != nil {
+			return fmt.Errorf("send digest to %d: %w", tgID, err)
+		}
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	if err := s.digest.DeleteDigestEvents(ids); err != nil {
+		return fmt.Errorf("delete digest events for %d: %w", tgID, err)
+	}
+	return s.markDigestSent(st, tgID, cutoff)
+}
+
+// markDigestSent запоминает разосланный слот, чтобы не слать дайджест дважды.
+func (s *Notifier) markDigestSent(st repository.UserSettings, tgID int64, slot time.Time) error {
+	if !st.DigestEnabled {
+		return nil
+	}
+	if err := s.digest.MarkDigestSent(tgID, slot); err != nil {
+		return fmt.Errorf("mark digest of %d sent: %w", tgID, err)
+	}
+	return nil
+}
+
+func (s *Notifier) addToDigest(tgID int64, n Notification) error {
+	err := s.digest.AddDigestEvent(repository.DigestEvent{
+		TelegramID: tgID,
+		Kind:       string(n.Kind),
+		Repo:       n.PR.Repo,
+		Number:     n.PR.Number,
+		Title:      n.PR.Title,
+		URL:        n.PR.URL,
+		Actor:      n.Actor.Login,
+		Text:       n.Text,
+		CreatedAt:  s.now(),
+	})
+	if err != nil {
+		return fmt.Errorf("add digest event for %d: %w", tgID, err)
+	}
+	return nil
+}
+
+func (s *Notifier) digestTime(st repository.UserSettings) int {
+	if st.DigestTime != nil {
+		return *st.DigestTime
+	}
+	return s.defaultDigest
+}
+
+// lastDigestSlot — последний момент дайджеста не позже now.
+func lastDigestSlot(now time.Time, minutes int, loc *time.Location) time.Time {
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), minutes/60, minutes%60, 0, 0, loc)
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot
+}
+
+var digestSections = []struct {
+	title string // ключ i18n
+	kinds []EventKind
+}{
+	{"digest.review_requested", []EventKind{EventReviewRequested}},
+	{"digest.reviews", []EventKind{EventApproved, EventCommented, EventReviewComment}},
+	{"digest.changes_requested", []EventKind{EventChangesRequested}},
+}
+
+// formatDigest группирует события по разделам и PR; pending попадают в раздел запросов review.
+// text отдаёт заголовки на языке получателя.
+func formatDigest(events []repository.DigestEvent, pending []ReviewRequest, text func(key string, args i18n.Args) string) []string {
+	if len(events) == 0 && len(pending) == 0 {
+		return nil
+	}
+
+	sectionOf := make(map[string]int)
+	for i, sec := range digestSections {
+		for _, k := range sec.kinds {
+			sectionOf[string(k)] = i
+		}
+	}
+	other := len(digestSections)
+
+	type prLine struct {
+		text   string
+		actors []string
+	}
+	lines := make([][]*prLine, other+1)
+	byPR := make([]map[string]*prLine, other+1)
+	for i := range byPR {
+		byPR[i] = make(map[string]*prLine)
+	}
+
+	requested := sectionOf[string(EventReviewRequested)]
+	for _, r := range pending {
+		l := &prLine{text: fmt.Sprintf("%s#%d %s — %s", r.Ref.Repo, r.Ref.Number, r.Ref.Title, r.Ref.URL)}
+		if r.Author != "" {
+			l.actors = []string{r.Author}
+		}
+		lines[requested] = append(lines[requested], l)
+	}
+
+	for _, e := range events {
+		sec, ok := sectionOf[e.Kind]
+		if !ok {
+			sec = other
+		}
+
+		if e.URL == "" || sec == other {
+			lines[sec] = append(lines[sec], &prLine{text: firstLine(e.Text)})
+			continue
+		}
+
+		l, ok := byPR[sec][e.URL]
+		if !ok {
+			l = &prLine{text: prTitle(e) + " — " + e.URL}
+			byPR[sec][e.URL] = l
+			lines[sec] = append(lines[sec], l)
+		}
+		if e.Actor != "" && !containsString(l.actors, e.Actor) {
+			l.actors = append(l.actors, e.Actor)
+		}
+	}
+
+	var parts []string
+	for i, sec := range lines {
+		if len(sec) == 0 {
+			continue
+		}
+		title := "digest.other"
+		if i < other {
+			title = digestSections[i].title
+		}
+
+		var sb strings.Builder
+		sb.WriteString(text(title, nil))
+		sb.WriteString(":")
+		for _, l := range sec {
+			sb.WriteString("\n• ")
+			sb.WriteString(l.text)
+			if len(l.actors) > 0 {
+				sb.WriteString(" (")
+				sb.WriteString(strings.Join(l.actors, ", "))
+				sb.WriteString(")")
+			}
+		}
+		parts = append(parts, sb.String())
+	}
+
+	header := text("digest.header", i18n.Args{"Count": len(events) + len(pending)})
+	return joinMessages(header, parts, maxMessageLen)
+}
+
+func prTitle(e repository.DigestEvent) string {
+	if e.Repo == "" || e.Number == 0 {
+		return e.Title
+	}
+	return fmt.Sprintf("%s#%d %s", e.Repo, e.Number, e.Title)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
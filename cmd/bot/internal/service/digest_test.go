@@ -0,0 +1,182 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+func TestNotifier_DigestCollectsAndSendsAtTime(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, memory.NewDigestRepo(), nil, sender, Config{DigestTime: 9 * 60})
+
+	if err := svc.SetDigest(1, true); err != nil {
+		t.Fatalf("SetDigest: %v", err)
+	}
+
+	evening := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return evening }
+
+	pr := PullRequestRef{Repo: "org/repo", Number: 7, Title: "Fix", URL: "https://github.com/org/repo/pull/7"}
+	for _, n := range []Notification{
+		{Kind: EventApproved, Actor: GitHubUser{Login: "alice"}, PR: pr, Text: "approved"},
+		{Kind: EventReviewComment, Actor: GitHubUser{Login: "bob"}, PR: pr, Text: "comment"},
+		{Kind: EventChangesRequested, Actor: GitHubUser{Login: "carol"}, PR: pr, Text: "changes"},
+	} {
+		if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("expected no immediate messages in digest mode, got %v", sender.sent[1])
+	}
+
+	if err := svc.SendDigests(evening.Add(time.Hour)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("digest sent before its time: %v", sender.sent[1])
+	}
+
+	if err := svc.SendDigests(time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected one digest message, got %v", sender.sent[1])
+	}
+	digest := sender.sent[1][0]
+	for _, want := range []string{"Новые review по вашим PR", "Запрошены изменения", "org/repo#7 Fix", "alice, bob"} {
+		if !strings.Contains(digest, want) {
+			t.Fatalf("digest %q does not contain %q", digest, want)
+		}
+	}
+
+	if err := svc.SendDigests(time.Date(2024, 3, 2, 9, 1, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("digest must be sent once, got %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_DigestOffFlushesPending(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, memory.NewDigestRepo(), nil, sender, Config{DigestTime: 9 * 60})
+
+	if err := svc.SetDigest(1, true); err != nil {
+		t.Fatalf("SetDigest: %v", err)
+	}
+	now := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Kind: EventMerged, Actor: GitHubUser{Login: "alice"}, Text: "merged"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	if err := svc.SetDigest(1, false); err != nil {
+		t.Fatalf("SetDigest: %v", err)
+	}
+	if err := svc.SendDigests(now.Add(time.Minute)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || !strings.Contains(sender.sent[1][0], "merged") {
+		t.Fatalf("expected pending events to be flushed, got %v", sender.sent[1])
+	}
+}
+
+type pendingStub []ReviewRequest
+
+func (p pendingStub) PendingReviews(int64) ([]ReviewRequest, error) { return p, nil }
+
+func TestNotifier_DigestReviewRequestsFromPullRequestState(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	// запрос по #7 сняли или выполнили до дайджеста, #8 всё ещё ждёт
+	pending := pendingStub{{
+		Ref:    PullRequestRef{Repo: "org/repo", Number: 8, Title: "Still open", URL: "https://github.com/org/repo/pull/8"},
+		Author: "dave",
+	}}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, memory.NewDigestRepo(), nil, sender, Config{DigestTime: 9 * 60, PendingReviews: pending})
+
+	if err := svc.SetDigest(1, true); err != nil {
+		t.Fatalf("SetDigest: %v", err)
+	}
+	evening := time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return evening }
+
+	done := PullRequestRef{Repo: "org/repo", Number: 7, Title: "Done", URL: "https://github.com/org/repo/pull/7"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Kind: EventReviewRequested, Actor: GitHubUser{Login: "alice"}, PR: done, Text: "review"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	if err := svc.SendDigests(time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected one digest message, got %v", sender.sent[1])
+	}
+	digest := sender.sent[1][0]
+	if !strings.Contains(digest, "Ждут вашего review") || !strings.Contains(digest, "org/repo#8 Still open") || !strings.Contains(digest, "(dave)") {
+		t.Fatalf("digest %q misses the pending review request", digest)
+	}
+	if strings.Contains(digest, "org/repo#7") {
+		t.Fatalf("digest %q lists a review request that is no longer pending", digest)
+	}
+}
+
+func TestNotifier_DigestWithOnlyPendingReviews(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "reviewer"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	// запрос пришёл ещё до прошлого дайджеста и до сих пор ждёт review
+	pending := pendingStub{{
+		Ref:    PullRequestRef{Repo: "org/repo", Number: 8, Title: "Still open", URL: "https://github.com/org/repo/pull/8"},
+		Author: "dave",
+	}}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, memory.NewDigestRepo(), nil, sender, Config{DigestTime: 9 * 60, PendingReviews: pending})
+	if err := svc.SetDigest(1, true); err != nil {
+		t.Fatalf("SetDigest: %v", err)
+	}
+
+	// дайджест только что включили: до следующего слота ничего не приходит
+	if err := svc.SendDigests(time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("digest sent right after enabling: %v", sender.sent[1])
+	}
+
+	for _, at := range []time.Time{
+		time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 9, 1, 0, 0, time.UTC),
+	} {
+		if err := svc.SendDigests(at); err != nil {
+			t.Fatalf("SendDigests: %v", err)
+		}
+	}
+	if len(sender.sent[1]) != 1 || !strings.Contains(sender.sent[1][0], "org/repo#8 Still open") {
+		t.Fatalf("expected one digest with the pending review, got %v", sender.sent[1])
+	}
+
+	if err := svc.SendDigests(time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SendDigests: %v", err)
+	}
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("expected the next day's digest, got %v", sender.sent[1])
+	}
+}
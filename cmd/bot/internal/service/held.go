@@ -0,0 +1,99 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+const (
+	heldBatchLimit = 500
+	// запас до лимита Telegram в 4096 символов
+	maxMessageLen = 4000
+)
+
+// FlushHeld отправляет уведомления, у которых закончились тихие часы,
+// одним сообщением на пользователя (или несколькими, если текст не помещается).
+func (s *Notifier) FlushHeld(now time.Time) error {
+	if s.held == nil {
+		return nil
+	}
+
+	due, err := s.held.DueHeld(now, heldBatchLimit)
+	if err != nil {
+		return fmt.Errorf("get held notifications: %w", err)
+	}
+
+	var (
+		order  []int64
+		byUser = make(map[int64][]repository.HeldNotification)
+	)
+	for _, n := range due {
+		if _, ok := byUser[n.TelegramID]; !ok {
+			order = append(order, n.TelegramID)
+		}
+		byUser[n.TelegramID] = append(byUser[n.TelegramID], n)
+	}
+
+	var (
+		errs []error
+		done []int64
+	)
+	for _, tgID := range order {
+		batch := byUser[tgID]
+		if err := s.sendHeld(tgID, batch); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, n := range batch {
+			done = append(done, n.ID)
+		}
+	}
+
+	if err := s.held.DeleteHeld(done); err != nil {
+		errs = append(errs, fmt.Errorf("delete held notifications: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Notifier) sendHeld(tgID int64, batch []repository.HeldNotification) error {
+	texts := make([]string, 0, len(batch))
+	for _, n := range batch {
+		texts = append(texts, n.Text)
+	}
+	header := s.Text(tgID, "notify.held_header", i18n.Args{"Count": len(batch)})
+
+	for _, msg := range joinMessages(header, texts, maxMessageLen) {
+		if err := s.sender.SendMessage(tgID, msg); err != nil {
+			return fmt.Errorf("send held notifications to %d: %w", tgID, err)
+		}
+	}
+	return nil
+}
+
+// joinMessages склеивает тексты через пустую строку, разбивая результат на части не длиннее limit.
+func joinMessages(header string, texts []string, limit int) []string {
+	var (
+		out []string
+		sb  strings.Builder
+	)
+	sb.WriteString(header)
+	for _, t := range texts {
+		if sb.Len() > 0 && sb.Len()+2+len(t) > limit {
+			out = append(out, sb.String())
+			sb.Reset()
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(t)
+	}
+	if sb.Len() > 0 {
+		out = append(out, sb.String())
+	}
+	return out
+}
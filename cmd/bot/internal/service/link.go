@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/github"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// DeviceFlow — часть GitHub OAuth device flow, нужная для /link.
+type DeviceFlow interface {
+	RequestDeviceCode(ctx context.Context, scope string) (github.DeviceCode, error)
+	PollAccessToken(ctx context.Context, deviceCode string) (string, error)
+	GetUser(ctx context.Context, token string) (github.User, error)
+}
+
+// Linker привязывает Telegram к GitHub аккаунту, подтверждённому через OAuth.
+type Linker struct {
+	flow        DeviceFlow
+	users       repository.UserRepository
+	credentials repository.CredentialsRepository
+	sender      TelegramSender
+	texts       Translator
+	scope       string
+	logger      *log.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[int64]*linkAttempt // незавершённые привязки по tgID
+}
+
+type linkAttempt struct {
+	cancel context.CancelFunc
+}
+
+// NewLinker создаёт сервис привязки. texts может быть nil — тогда ответы на языке по умолчанию.
+func NewLinker(
+	flow DeviceFlow,
+	users repository.UserRepository,
+	credentials repository.CredentialsRepository,
+	sender TelegramSender,
+	texts Translator,
+	scope string,
+	logger *log.Logger,
+) *Linker {
+	if logger == nil {
+		logger = log.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Linker{
+		flow:        flow,
+		users:       users,
+		credentials: credentials,
+		sender:      sender,
+		texts:       translatorOrDefault(texts),
+		scope:       scope,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+		active:      make(map[int64]*linkAttempt),
+	}
+}
+
+// StartLink запрашивает device code и в фоне ждёт, пока пользователь подтвердит вход.
+// Результат приходит пользователю отдельным сообщением.
+func (l *Linker) StartLink(tgID int64) (github.DeviceCode, error) {
+	dc, err := l.flow.RequestDeviceCode(l.ctx, l.scope)
+	if err != nil {
+		return github.DeviceCode{}, err
+	}
+	if dc.ExpiresIn <= 0 {
+		dc.ExpiresIn = 15 * time.Minute
+	}
+	if dc.Interval <= 0 {
+		dc.Interval = 5 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, dc.ExpiresIn)
+	attempt := &linkAttempt{cancel: cancel}
+
+	// повторный /link отменяет предыдущую попытку
+	l.mu.Lock()
+	if prev, ok := l.active[tgID]; ok {
+		prev.cancel()
+	}
+	l.active[tgID] = attempt
+	l.mu.Unlock()
+
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer l.finish(tgID, attempt)
+
+		l.poll(ctx, tgID, dc)
+	}()
+	return dc, nil
+}
+
+// Shutdown прерывает все незавершённые привязки.
+func (l *Linker) Shutdown() {
+	l.cancel()
+	l.wg.Wait()
+}
+
+func (l *Linker) finish(tgID int64, attempt *linkAttempt) {
+	attempt.cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[tgID] == attempt {
+		delete(l.active, tgID)
+	}
+}
+
+func (l *Linker) poll(ctx context.Context, tgID int64, dc github.DeviceCode) {
+	interval := dc.Interval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				l.reply(tgID, "link.expired", nil)
+			}
+			return
+		case <-timer.C:
+		}
+
+		token, err := l.flow.PollAccessToken(ctx, dc.DeviceCode)
+		switch {
+		case err == nil:
+			l.complete(ctx, tgID, token)
+			return
+		case errors.Is(err, github.ErrAuthorizationPending):
+		case errors.Is(err, github.ErrSlowDown):
+			interval += 5 * time.Second
+		case errors.Is(err, github.ErrExpiredToken):
+			l.reply(tgID, "link.expired", nil)
+			return
+		case errors.Is(err, github.ErrAccessDenied):
+			l.reply(tgID, "link.denied", nil)
+			return
+		default:
+			if ctx.Err() != nil {
+				continue
+			}
+			l.logger.Printf("[link] poll token for %d error: %v", tgID, err)
+			l.reply(tgID, "link.failed", nil)
+			return
+		}
+		timer.Reset(interval)
+	}
+}
+
+func (l *Linker) complete(ctx context.Context, tgID int64, token string) {
+	u, err := l.flow.GetUser(ctx, token)
+	if err == nil {
+		err = l.save(tgID, u, token)
+	}
+	if err != nil {
+		l.logger.Printf("[link] complete link for %d error: %v", tgID, err)
+		l.reply(tgID, "link.failed", nil)
+		return
+	}
+	l.reply(tgID, "link.done", i18n.Args{"Login": u.Login})
+}
+
+func (l *Linker) save(tgID int64, u github.User, token string) error {
+	err := l.credentials.SaveCredentials(repository.GitHubCredentials{
+		TelegramID:   tgID,
+		GitHubUserID: u.ID,
+		GitHubLogin:  u.Login,
+		AccessToken:  token,
+	})
+	if err != nil {
+		return err
+	}
+	return l.users.SaveBinding(repository.UserBinding{
+		TelegramID:   tgID,
+		GitHubLogin:  u.Login,
+		GitHubUserID: u.ID,
+		Verified:     true,
+	})
+}
+
+func (l *Linker) reply(tgID int64, key string, args i18n.Args) {
+	if err := l.sender.SendMessage(tgID, l.texts.Text(tgID, key, args)); err != nil {
+		l.logger.Printf("[link] send message to %d error: %v", tgID, err)
+	}
+}
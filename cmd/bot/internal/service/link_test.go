@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/github"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type fakeDeviceFlow struct {
+	mu      sync.Mutex
+	pending int
+	err     error
+}
+
+func (f *fakeDeviceFlow) RequestDeviceCode(ctx context.Context, scope string) (github.DeviceCode, error) {
+	return github.DeviceCode{
+		DeviceCode:      "dev",
+		UserCode:        "ABCD-1234",
+		VerificationURI: "https://github.com/login/device",
+		ExpiresIn:       time.Second,
+		Interval:        time.Millisecond,
+	}, nil
+}
+
+func (f *fakeDeviceFlow) PollAccessToken(ctx context.Context, deviceCode string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	if f.pending > 0 {
+		f.pending--
+		return "", github.ErrAuthorizationPending
+	}
+	return "tok", nil
+}
+
+func (f *fakeDeviceFlow) GetUser(ctx context.Context, token string) (github.User, error) {
+	return github.User{ID: 42, Login: "Octocat"}, nil
+}
+
+type syncSender struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (s *syncSender) SendMessage(chatID int64, text string, _ ...[]Button) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, text)
+	return nil
+}
+
+func TestLinker_SavesVerifiedBinding(t *testing.T) {
+	users := memory.NewUserRepo()
+	creds := memory.NewCredentialsRepo()
+	sender := &syncSender{}
+	l := NewLinker(&fakeDeviceFlow{pending: 2}, users, creds, sender, nil, "read:user", log.New(io.Discard, "", 0))
+
+	dc, err := l.StartLink(7)
+	if err != nil {
+		t.Fatalf("StartLink: %v", err)
+	}
+	if dc.UserCode != "ABCD-1234" {
+		t.Fatalf("unexpected user code %q", dc.UserCode)
+	}
+
+	l.wg.Wait()
+
+	b, err := users.GetByTelegramID(7)
+	if err != nil {
+		t.Fatalf("GetByTelegramID: %v", err)
+	}
+	if b.GitHubLogin != "octocat" || !b.Verified {
+		t.Fatalf("unexpected binding: %+v", b)
+	}
+
+	c, err := creds.GetCredentials(7)
+	if err != nil {
+		t.Fatalf("GetCredentials: %v", err)
+	}
+	if c.GitHubUserID != 42 || c.AccessToken != "tok" {
+		t.Fatalf("unexpected credentials: %+v", c)
+	}
+
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0], "подтверждён") {
+		t.Fatalf("unexpected messages: %v", sender.sent)
+	}
+}
+
+func TestLinker_AccessDenied(t *testing.T) {
+	users := memory.NewUserRepo()
+	sender := &syncSender{}
+	l := NewLinker(&fakeDeviceFlow{err: github.ErrAccessDenied}, users, memory.NewCredentialsRepo(), sender, nil, "", log.New(io.Discard, "", 0))
+
+	if _, err := l.StartLink(7); err != nil {
+		t.Fatalf("StartLink: %v", err)
+	}
+	l.wg.Wait()
+
+	if _, err := users.GetByTelegramID(7); err == nil {
+		t.Fatalf("binding must not be saved")
+	}
+	if len(sender.sent) != 1 || !strings.Contains(sender.sent[0], "отменена") {
+		t.Fatalf("unexpected messages: %v", sender.sent)
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionRe — @login по правилам GitHub: буквы, цифры и дефисы, до 39 символов.
+// Перед @ не должно быть буквы или цифры (почта) и слэша; @org/team не упоминание пользователя.
+var mentionRe = regexp.MustCompile(`(?:^|[^\w/@.])@([A-Za-z0-9](?:[A-Za-z0-9-]{0,38}))\b(/)?`)
+
+// inlineCodeRe — `код` внутри строки.
+var inlineCodeRe = regexp.MustCompile("`[^`\n]*`")
+
+// ParseMentions возвращает упомянутые в тексте логины без повторов, в порядке появления.
+// Код и цитаты пропускаются: GitHub их тоже не считает упоминаниями.
+func ParseMentions(body string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, m := range mentionRe.FindAllStringSubmatch(stripCodeAndQuotes(body), -1) {
+		if m[2] != "" {
+			continue // @org/team
+		}
+		login := normalizeLogin(m[1])
+		if seen[login] {
+			continue
+		}
+		seen[login] = true
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// stripCodeAndQuotes убирает из markdown блоки кода (``` и ~~~, а также с отступом
+// в 4 пробела), `код` и строки цитат.
+func stripCodeAndQuotes(body string) string {
+	var (
+		sb       strings.Builder
+		fence    string // открывающий забор текущего блока кода
+		indented bool   // внутри блока кода с отступом
+		// отступ начинает блок кода только после пустой строки: внутри абзаца это продолжение текста
+		blank = true
+	)
+	for _, line := range strings.Split(body, "\n") {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+				blank = true
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			blank = true
+			sb.WriteByte('\n')
+			continue
+		}
+		if indentWidth(line) >= 4 && (blank || indented) {
+			indented = true
+			continue
+		}
+		indented, blank = false, false
+		if f, ok := openingFence(line); ok {
+			fence = f
+			continue
+		}
+		if strings.HasPrefix(trimmed, ">") {
+			continue
+		}
+		sb.WriteString(inlineCodeRe.ReplaceAllString(line, " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// openingFence возвращает забор (``` или ~~~ любой длины от трёх), которым открывается блок кода.
+func openingFence(line string) (string, bool) {
+	if indentWidth(line) > 3 {
+		return "", false
+	}
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "```") && !strings.HasPrefix(trimmed, "~~~") {
+		return "", false
+	}
+	return fenceRun(trimmed), true
+}
+
+// closesFence сообщает, закрывает ли строка блок: тот же символ, забор не короче открывающего
+// и ничего после него. ``` внутри блока, открытого ````, остаётся кодом.
+func closesFence(line, fence string) bool {
+	if indentWidth(line) > 3 {
+		return false
+	}
+	trimmed := strings.TrimSpace(line)
+	run := fenceRun(trimmed)
+	return run != "" && run[0] == fence[0] && len(run) >= len(fence) && run == trimmed
+}
+
+// fenceRun — ведущая серия одинаковых символов ` или ~.
+func fenceRun(s string) string {
+	if s == "" || (s[0] != '`' && s[0] != '~') {
+		return ""
+	}
+	n := 1
+	for n < len(s) && s[n] == s[0] {
+		n++
+	}
+	return s[:n]
+}
+
+// indentWidth — ширина отступа строки; табуляция добивает до кратного четырём.
+func indentWidth(line string) int {
+	w := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			w++
+		case '\t':
+			w += 4 - w%4
+		default:
+			return w
+		}
+	}
+	return w
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	cases := []struct {
+		body string
+		want []string
+	}{
+		{"@alice can you check this?", []string{"alice"}},
+		{"cc @Bob, @alice and @bob", []string{"Bob", "alice"}},
+		{"mail me at dev@example.com", nil},
+		{"ping @org/backend please", nil},
+		{"(@carol-x) done.", []string{"carol-x"}},
+		{"no mentions here", nil},
+		{"> @alice wrote this\n@bob agreed", []string{"bob"}},
+		{"see `@alice` and\n```go\n// @carol\n```\n@dave", []string{"dave"}},
+		{"~~~\n@alice\n~~~\n  > @bob\n@erin", []string{"erin"}},
+		// ``` внутри блока из четырёх ` его не закрывает
+		{"````md\n```\n@alice\n```\n@bob\n````\n@carol", []string{"carol"}},
+		{"```\n@alice\n```go\n@bob\n```\n@carol", []string{"carol"}},
+		// блок кода с отступом после пустой строки; внутри абзаца отступ — просто текст
+		{"example:\n\n    @alice\n\n    @bob\n@carol", []string{"carol"}},
+		{"paragraph\n    @alice", []string{"alice"}},
+		{"\t@alice\n@bob", []string{"bob"}},
+	}
+	for _, tc := range cases {
+		if got := ParseMentions(tc.body); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseMentions(%q) = %v, want %v", tc.body, got, tc.want)
+		}
+	}
+}
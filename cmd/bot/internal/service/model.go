@@ -1,7 +1,199 @@
 package service
 
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+)
+
 type PROpenAssignedEvent struct {
 	AssigneeLogin string
 	Title         string
 	URL           string
 }
+
+// GitHubUser — пользователь GitHub из webhook. ID неизменен, логин может смениться.
+// ID == 0, если он неизвестен (например, участники команд из конфига).
+type GitHubUser struct {
+	ID    int64
+	Login string
+}
+
+func (u GitHubUser) key() string {
+	if u.ID != 0 {
+		return fmt.Sprintf("id:%d", u.ID)
+	}
+	return "login:" + normalizeLogin(u.Login)
+}
+
+// Same сравнивает пользователей по ID, если он известен у обоих, иначе по логину.
+func (u GitHubUser) Same(other GitHubUser) bool {
+	if u.ID != 0 && other.ID != 0 {
+		return u.ID == other.ID
+	}
+	return u.Login != "" && normalizeLogin(u.Login) == normalizeLogin(other.Login)
+}
+
+// EventKind — тип события, который пользователь может отключить в /settings.
+type EventKind string
+
+const (
+	EventAssigned             EventKind = "assigned"
+	EventReviewRequested      EventKind = "review_requested"
+	EventReviewRequestRemoved EventKind = "review_request_removed"
+	EventApproved             EventKind = "approved"
+	EventChangesRequested     EventKind = "changes_requested"
+	EventCommented            EventKind = "commented"
+	EventReviewComment        EventKind = "review_comment"
+	EventMerged               EventKind = "merged"
+	EventCIFailed             EventKind = "ci_failed"
+	EventReviewReminder       EventKind = "review_reminder"
+	EventClosed               EventKind = "closed"
+	EventReopened             EventKind = "reopened"
+	EventReadyForReview       EventKind = "ready_for_review"
+	EventConvertedToDraft     EventKind = "converted_to_draft"
+	EventIssueAssigned        EventKind = "issue_assigned"
+	EventIssueClosed          EventKind = "issue_closed"
+	EventIssueComment         EventKind = "issue_comment"
+	EventMentioned            EventKind = "mentioned"
+)
+
+// EventKinds — все настраиваемые типы событий в порядке показа в /settings.
+var EventKinds = []EventKind{
+	EventAssigned,
+	EventReviewRequested,
+	EventReviewRequestRemoved,
+	EventApproved,
+	EventChangesRequested,
+	EventCommented,
+	EventReviewComment,
+	EventMerged,
+	EventClosed,
+	EventReopened,
+	EventReadyForReview,
+	EventConvertedToDraft,
+	EventIssueAssigned,
+	EventIssueClosed,
+	EventIssueComment,
+	EventMentioned,
+	EventCIFailed,
+	EventReviewReminder,
+}
+
+func ParseEventKind(s string) (EventKind, bool) {
+	for _, k := range EventKinds {
+		if string(k) == s {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// PullRequestRef — PR, к которому относится уведомление.
+type PullRequestRef struct {
+	Repo   string // owner/name
+	Number int
+	Title  string
+	URL    string
+}
+
+// PullRequestSnapshot — PR в том виде, в каком он пришёл в webhook.
+type PullRequestSnapshot struct {
+	ID        int64
+	Ref       PullRequestRef
+	Author    GitHubUser
+	Assignees []GitHubUser
+	Reviewers []GitHubUser // requested_reviewers
+	Draft     bool
+	State     string // open или closed
+	Merged    bool
+	HeadSHA   string
+	Mergeable *bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Button — inline-кнопка под сообщением: ссылка (URL) или callback (Data).
+type Button struct {
+	Text string
+	Key  string // текст из i18n на языке получателя, если задан
+	URL  string
+	Data string
+}
+
+// Notification — одно событие для отправки получателям.
+type Notification struct {
+	Kind  EventKind  // пустой Kind не фильтруется настройками
+	Actor GitHubUser // тот, кто совершил действие
+	PR    PullRequestRef
+	Text  string
+	// Key — шаблон текста из i18n; если задан, Text собирается из него на языке получателя.
+	Key     string
+	Args    i18n.Args
+	Buttons [][]Button // не сохраняются в дайджесте и за тихие часы
+	// CommentID — review comment, на который можно ответить реплаем в Telegram.
+	CommentID int64
+	// Snippet — короткий текст комментария для склеенного сообщения.
+	Snippet string
+	// ForAuthor — получатель автор PR: шаблоны получают .Own и пишут «ваш PR».
+	ForAuthor bool
+	// Issue — уведомление об issue, а не о PR: кнопок действий с PR под ним нет.
+	Issue bool
+	// excludeSelf — получатель выбран по RecipientPolicy с ExcludeCommenter: своё действие
+	// ему не шлётся, если он сам не включил /selfnotify.
+	excludeSelf bool
+}
+
+// Participants — участники PR, из которых по RecipientPolicy выбираются получатели.
+type Participants struct {
+	Author    GitHubUser
+	Assignees []GitHubUser
+	Reviewers []GitHubUser
+}
+
+// forRecipient отмечает, что уведомление уходит автору PR, а не ревьюеру или исполнителю.
+func (p Participants) forRecipient(u GitHubUser, n Notification) Notification {
+	n.ForAuthor = p.Author.Same(u)
+	return n
+}
+
+type RecipientPolicy struct {
+	Author    bool
+	Assignees bool
+	Reviewers bool
+	// ExcludeCommenter не шлёт review и комментарии их автору, пока тот не выбрал /selfnotify.
+	ExcludeCommenter bool
+}
+
+func (p RecipientPolicy) Recipients(pp Participants) []GitHubUser {
+	var candidates []GitHubUser
+	if p.Author {
+		candidates = append(candidates, pp.Author)
+	}
+	if p.Assignees {
+		candidates = append(candidates, pp.Assignees...)
+	}
+	if p.Reviewers {
+		candidates = append(candidates, pp.Reviewers...)
+	}
+
+	seen := make(map[string]struct{}, len(candidates))
+	out := make([]GitHubUser, 0, len(candidates))
+	for _, u := range candidates {
+		if u.ID == 0 && normalizeLogin(u.Login) == "" {
+			continue
+		}
+		if _, ok := seen[u.key()]; ok {
+			continue
+		}
+		seen[u.key()] = struct{}{}
+		out = append(out, u)
+	}
+	return out
+}
+
+func normalizeLogin(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
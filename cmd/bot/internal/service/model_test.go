@@ -0,0 +1,55 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecipientPolicy_Recipients(t *testing.T) {
+	author := GitHubUser{ID: 1, Login: "Author"}
+	owner := GitHubUser{ID: 2, Login: "owner"}
+	reviewer := GitHubUser{ID: 3, Login: "reviewer"}
+	other := GitHubUser{Login: "other"}
+
+	pp := Participants{
+		Author:    author,
+		Assignees: []GitHubUser{owner, {ID: 1, Login: "author"}},
+		Reviewers: []GitHubUser{reviewer, other, {ID: 2, Login: "Owner"}, {Login: "OTHER"}},
+	}
+
+	tests := []struct {
+		name   string
+		policy RecipientPolicy
+		want   []GitHubUser
+	}{
+		{
+			name:   "author and assignees",
+			policy: RecipientPolicy{Author: true, Assignees: true},
+			want:   []GitHubUser{author, owner},
+		},
+		{
+			name:   "reviewers",
+			policy: RecipientPolicy{Reviewers: true},
+			want:   []GitHubUser{reviewer, other, {ID: 2, Login: "Owner"}},
+		},
+		{
+			name:   "everyone deduplicated",
+			policy: RecipientPolicy{Author: true, Assignees: true, Reviewers: true},
+			want:   []GitHubUser{author, owner, reviewer, other},
+		},
+		{
+			name:   "nobody",
+			policy: RecipientPolicy{},
+			want:   []GitHubUser{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Recipients(pp)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
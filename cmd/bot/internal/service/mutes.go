@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+var (
+	muteTargetRe   = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+(#[1-9][0-9]*)?$`)
+	muteDurationRe = regexp.MustCompile(`^([1-9][0-9]*)([mhdw])$`)
+)
+
+var errMutesDisabled = errors.New("mutes are not configured")
+
+var (
+	// ErrBadMuteTarget — цель /mute не похожа на owner/repo или owner/repo#номер.
+	ErrBadMuteTarget = errors.New("mute target must be owner/repo or owner/repo#number")
+	// ErrBadMuteDuration — срок /mute не вида 30m, 2h, 2d или 1w.
+	ErrBadMuteDuration = errors.New("mute duration must look like 30m, 2h, 2d or 1w")
+)
+
+// ParseMuteTarget проверяет цель /mute: "owner/repo#12" (PR или issue) или "owner/repo".
+func ParseMuteTarget(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if !muteTargetRe.MatchString(s) {
+		return "", fmt.Errorf("%w, got %q", ErrBadMuteTarget, s)
+	}
+	return strings.ToLower(s), nil
+}
+
+// ParseMuteDuration разбирает срок mute: 30m, 2h, 2d, 1w.
+func ParseMuteDuration(s string) (time.Duration, error) {
+	m := muteDurationRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, fmt.Errorf("%w, got %q", ErrBadMuteDuration, s)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, fmt.Errorf("%w, got %q", ErrBadMuteDuration, s)
+	}
+	unit := map[string]time.Duration{"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour}[m[2]]
+	return time.Duration(n) * unit, nil
+}
+
+// Mute выключает уведомления по target на срок d; d == 0 — бессрочно.
+func (s *Notifier) Mute(tgID int64, target string, d time.Duration) (repository.Mute, error) {
+	if s.mutes == nil {
+		return repository.Mute{}, errMutesDisabled
+	}
+	now := s.now()
+	m := repository.Mute{TelegramID: tgID, Target: strings.ToLower(target), CreatedAt: now}
+	if d > 0 {
+		m.ExpiresAt = now.Add(d)
+	}
+	if err := s.mutes.SaveMute(m); err != nil {
+		return repository.Mute{}, err
+	}
+	return m, nil
+}
+
+// Unmute снимает mute и сообщает, был ли он.
+func (s *Notifier) Unmute(tgID int64, target string) (bool, error) {
+	if s.mutes == nil {
+		return false, errMutesDisabled
+	}
+	return s.mutes.DeleteMute(tgID, target)
+}
+
+// Mutes возвращает действующие mute пользователя.
+func (s *Notifier) Mutes(tgID int64) ([]repository.Mute, error) {
+	if s.mutes == nil {
+		return nil, nil
+	}
+	return s.mutes.ListMutes(tgID, s.now())
+}
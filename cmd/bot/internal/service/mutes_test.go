@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+func TestParseMuteTarget(t *testing.T) {
+	for in, want := range map[string]string{
+		"Org/Repo#12":  "org/repo#12",
+		"org/my.repo":  "org/my.repo",
+		" org/repo#1 ": "org/repo#1",
+	} {
+		got, err := ParseMuteTarget(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMuteTarget(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "repo", "repo#12", "org/repo#", "org/repo#0", "org/repo/x"} {
+		if _, err := ParseMuteTarget(in); !errors.Is(err, ErrBadMuteTarget) {
+			t.Fatalf("ParseMuteTarget(%q): expected ErrBadMuteTarget, got %v", in, err)
+		}
+	}
+}
+
+func TestParseMuteDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30m": 30 * time.Minute,
+		"2h":  2 * time.Hour,
+		"2d":  48 * time.Hour,
+		"1W":  7 * 24 * time.Hour,
+	} {
+		got, err := ParseMuteDuration(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMuteDuration(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "2", "0d", "-1d", "2y"} {
+		if _, err := ParseMuteDuration(in); !errors.Is(err, ErrBadMuteDuration) {
+			t.Fatalf("ParseMuteDuration(%q): expected ErrBadMuteDuration, got %v", in, err)
+		}
+	}
+}
+
+func TestNotifier_MuteRepoWithExpiry(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, memory.NewMuteRepo(), sender, Config{})
+	now := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if _, err := svc.Mute(1, "org/repo", 48*time.Hour); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	n := Notification{PR: PullRequestRef{Repo: "Org/Repo", Number: 3}, Text: "comment"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("expected muted repo to send nothing, got %v", sender.sent[1])
+	}
+
+	now = now.Add(49 * time.Hour)
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected message after mute expired, got %v", sender.sent[1])
+	}
+
+	if _, err := svc.Mute(1, "org/repo#3", 0); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	mutes, err := svc.Mutes(1)
+	if err != nil || len(mutes) != 1 || mutes[0].Target != "org/repo#3" || !mutes[0].ExpiresAt.IsZero() {
+		t.Fatalf("unexpected mutes %+v (%v)", mutes, err)
+	}
+	if ok, err := svc.Unmute(1, "Org/Repo#3"); err != nil || !ok {
+		t.Fatalf("Unmute: ok=%v err=%v", ok, err)
+	}
+	if ok, _ := svc.Unmute(1, "org/repo#3"); ok {
+		t.Fatalf("expected nothing to unmute")
+	}
+}
@@ -1,23 +1,115 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
 	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
 )
 
+// TelegramSender отправляет сообщение; каждый элемент buttons — ряд inline-кнопок.
 type TelegramSender interface {
-	SendMessage(chatID int64, text string) error
+	SendMessage(chatID int64, text string, buttons ...[]Button) error
+}
+
+// RefSender — отправитель, который запоминает, к чему относится сообщение,
+// чтобы потом найти его по ответу пользователя.
+type RefSender interface {
+	SendMessageRef(chatID int64, text string, ref repository.MessageRef, buttons ...[]Button) error
+}
+
+type Config struct {
+	Teams      map[string][]string // slug команды -> github логины участников
+	Recipients RecipientPolicy
+	SelfNotify bool // слать ли пользователю его же действия, если он не менял настройку (/selfnotify)
+	// RequireVerified — слать только на привязки, подтверждённые через /link.
+	RequireVerified bool
+	// Urgent — типы событий, которые приходят и во время тихих часов, и в режиме дайджеста.
+	Urgent []EventKind
+	// DigestTime — время дайджеста по умолчанию, минуты от полуночи.
+	DigestTime int
+	// CommentWindow — за сколько склеивать комментарии к коду по одному PR; 0 — не склеивать.
+	CommentWindow time.Duration
+	// Texts — тексты сообщений; nil — встроенные.
+	Texts *i18n.Catalog
+	// PendingReviews — открытые запросы review для дайджеста; nil — раздел собирается из накопленных событий.
+	PendingReviews PendingReviewLister
+}
+
+// PendingReviewLister отдаёт открытые PR, которые сейчас ждут review пользователя.
+type PendingReviewLister interface {
+	PendingReviews(tgID int64) ([]ReviewRequest, error)
 }
 
 type Notifier struct {
-	users  repository.UserRepository
-	sender TelegramSender
+	users      repository.UserRepository
+	settings   repository.SettingsRepository
+	held       repository.HeldRepository
+	digest     repository.DigestRepository
+	mutes      repository.MuteRepository
+	sender     TelegramSender
+	teams      map[string][]string
+	policy     RecipientPolicy
+	selfNotify bool
+	verified   bool
+	urgent     map[EventKind]bool
+	comments   *commentBatcher
+	texts      *i18n.Catalog
+	pending    PendingReviewLister
+	now        func() time.Time
+
+	defaultDigest int
 }
 
-func NewNotifier(users repository.UserRepository, sender TelegramSender) *Notifier {
-	return &Notifier{users: users, sender: sender}
+// NewNotifier создаёт сервис уведомлений. held, digest и mutes могут быть nil —
+// тогда тихие часы, дайджест и mute не действуют.
+func NewNotifier(
+	users repository.UserRepository,
+	settings repository.SettingsRepository,
+	held repository.HeldRepository,
+	digest repository.DigestRepository,
+	mutes repository.MuteRepository,
+	sender TelegramSender,
+	cfg Config,
+) *Notifier {
+	teams := make(map[string][]string, len(cfg.Teams))
+	for slug, members := range cfg.Teams {
+		teams[strings.ToLower(strings.TrimSpace(slug))] = members
+	}
+	urgent := make(map[EventKind]bool, len(cfg.Urgent))
+	for _, k := range cfg.Urgent {
+		urgent[k] = true
+	}
+	var comments *commentBatcher
+	if cfg.CommentWindow > 0 {
+		comments = newCommentBatcher(cfg.CommentWindow)
+	}
+	texts := cfg.Texts
+	if texts == nil {
+		texts = i18n.Default()
+	}
+	return &Notifier{
+		users:      users,
+		settings:   settings,
+		held:       held,
+		digest:     digest,
+		mutes:      mutes,
+		sender:     sender,
+		teams:      teams,
+		policy:     cfg.Recipients,
+		selfNotify: cfg.SelfNotify,
+		verified:   cfg.RequireVerified,
+		urgent:     urgent,
+		comments:   comments,
+		texts:      texts,
+		pending:    cfg.PendingReviews,
+		now:        time.Now,
+
+		defaultDigest: cfg.DigestTime,
+	}
 }
 
 func (s *Notifier) SetGitHubLogin(tgID int64, login string) error {
@@ -25,30 +117,422 @@ func (s *Notifier) SetGitHubLogin(tgID int64, login string) error {
 	if login == "" {
 		return fmt.Errorf("empty login")
 	}
+
+	// повторный /setgithub с тем же логином не снимает подтверждение
+	if cur, err := s.users.GetByTelegramID(tgID); err == nil && cur.Verified && normalizeLogin(cur.GitHubLogin) == normalizeLogin(login) {
+		return nil
+	}
+
 	return s.users.SaveBinding(repository.UserBinding{
 		TelegramID:  tgID,
 		GitHubLogin: login,
 	})
 }
 
-func (s *Notifier) GetMe(tgID int64) (string, error) {
-	b, err := s.users.GetByTelegramID(tgID)
+func (s *Notifier) GetMe(tgID int64) (*repository.UserBinding, error) {
+	return s.users.GetByTelegramID(tgID)
+}
+
+func (s *Notifier) SetSelfNotify(tgID int64, enabled bool) error {
+	st, err := s.settings.GetSettings(tgID)
 	if err != nil {
-		return "", err
+		return err
+	}
+	st.SelfNotify = &enabled
+	return s.settings.SaveSettings(st)
+}
+
+// Preferences возвращает включённость каждого типа событий для пользователя.
+func (s *Notifier) Preferences(tgID int64) (map[EventKind]bool, error) {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs := make(map[EventKind]bool, len(EventKinds))
+	for _, k := range EventKinds {
+		prefs[k] = true
+	}
+	for _, d := range st.DisabledEvents {
+		if k, ok := ParseEventKind(d); ok {
+			prefs[k] = false
+		}
+	}
+	return prefs, nil
+}
+
+// ToggleEvent включает или выключает тип событий и возвращает новое состояние.
+func (s *Notifier) ToggleEvent(tgID int64, kind EventKind) (bool, error) {
+	if _, ok := ParseEventKind(string(kind)); !ok {
+		return false, fmt.Errorf("unknown event kind %q", kind)
+	}
+
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return false, err
+	}
+
+	disabled := make([]string, 0, len(st.DisabledEvents)+1)
+	enabled := false
+	for _, d := range st.DisabledEvents {
+		if d == string(kind) {
+			enabled = true
+			continue
+		}
+		disabled = append(disabled, d)
+	}
+	if !enabled {
+		disabled = append(disabled, string(kind))
+	}
+	st.DisabledEvents = disabled
+
+	if err := s.settings.SaveSettings(st); err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+// QuietHours возвращает тихие часы пользователя или nil, если они выключены.
+func (s *Notifier) QuietHours(tgID int64) (*QuietHours, error) {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return nil, err
+	}
+	return quietHoursFrom(st)
+}
+
+// SetQuietHours сохраняет тихие часы и часовой пояс; nil выключает тихие часы.
+func (s *Notifier) SetQuietHours(tgID int64, q *QuietHours) error {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return err
 	}
-	return b.GitHubLogin, nil
+	if q == nil {
+		st.QuietStart, st.QuietEnd = nil, nil
+	} else {
+		start, end := q.Start, q.End
+		st.QuietStart, st.QuietEnd = &start, &end
+		st.Timezone = q.location().String()
+	}
+	return s.settings.SaveSettings(st)
 }
 
-func (s *Notifier) NotifyAssignee(assigneeLogin, msg string) error {
-	bindings, err := s.users.GetByGitHubLogin(assigneeLogin)
+// SetTimezone сохраняет часовой пояс пользователя (IANA-имя).
+func (s *Notifier) SetTimezone(tgID int64, tz string) error {
+	loc, err := loadLocation(tz)
 	if err != nil {
 		return err
 	}
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return err
+	}
+	st.Timezone = loc.String()
+	return s.settings.SaveSettings(st)
+}
+
+// Timezone возвращает сохранённый часовой пояс пользователя (пусто — UTC).
+func (s *Notifier) Timezone(tgID int64) (string, error) {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return "", err
+	}
+	return st.Timezone, nil
+}
+
+func (s *Notifier) NotifyAssignee(assignee GitHubUser, n Notification) error {
+	_, err := s.notifyUser(assignee, n)
+	return err
+}
+
+// notifyUser шлёт уведомление по всем привязкам пользователя и возвращает, скольким
+// из них оно ушло: отключённый тип события, mute или своё действие отправку пропускают.
+func (s *Notifier) notifyUser(assignee GitHubUser, n Notification) (int, error) {
+	bindings, err := s.bindingsFor(assignee)
+	if err != nil {
+		return 0, err
+	}
+
+	bindings = s.trustedBindings(bindings)
+	self := n.Actor.Same(assignee)
+
+	// ошибка по одной привязке не должна мешать остальным
+	var (
+		errs []error
+		sent int
+	)
+	for _, b := range bindings {
+		st, err := s.settings.GetSettings(b.TelegramID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get settings for %d: %w", b.TelegramID, err))
+			continue
+		}
+		if !wantsEvent(st, n.Kind) {
+			continue
+		}
+		if self && !s.wantsSelfNotify(st, n) {
+			continue
+		}
+		muted, err := s.muted(b.TelegramID, n.PR)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get mutes for %d: %w", b.TelegramID, err))
+			continue
+		}
+		if muted {
+			continue
+		}
+
+		msg := s.localize(st, n)
+		if s.comments != nil {
+			switch n.Kind {
+			case EventReviewComment:
+				s.comments.add(st, msg, s.now())
+				sent++
+				continue
+			case EventApproved, EventChangesRequested, EventCommented:
+				if snippets := s.comments.takeForReview(st.TelegramID, n, s.now()); len(snippets) > 0 {
+					msg.Text += s.reviewWithComments(st, snippets)
+				}
+			}
+		}
+		if err := s.deliver(st, msg); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *Notifier) NotifyTeam(teamSlug string, n Notification) error {
+	members, ok := s.teams[strings.ToLower(strings.TrimSpace(teamSlug))]
+	if !ok {
+		return fmt.Errorf("team %q is not configured", teamSlug)
+	}
+
+	var errs []error
+	for _, login := range members {
+		if err := s.NotifyAssignee(GitHubUser{Login: login}, n); err != nil {
+			errs = append(errs, fmt.Errorf("notify team member %s: %w", login, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Notifier) NotifyParticipants(p Participants, n Notification) error {
+	var errs []error
+	for _, u := range s.policy.Recipients(p) {
+		if err := s.NotifyAssignee(u, s.forPolicyRecipient(p, u, n)); err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", u.Login, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NotifyWithMentions шлёт упомянутым пользователям отдельное сообщение mention,
+// а остальным получателям по RecipientPolicy — обычное n. Участник, которому упоминание
+// не ушло (например, упоминания выключены в /settings), получает обычное n.
+func (s *Notifier) NotifyWithMentions(p Participants, mentioned []string, n, mention Notification) error {
+	var errs []error
+	notified := make(map[string]bool, len(mentioned))
+	for _, login := range mentioned {
+		u := GitHubUser{Login: login}
+		sent, err := s.notifyUser(u, mention)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notify mentioned %s: %w", login, err))
+		}
+		if sent > 0 {
+			notified[u.key()] = true
+		}
+	}
+
+	for _, u := range s.policy.Recipients(p) {
+		if notified[GitHubUser{Login: u.Login}.key()] {
+			continue
+		}
+		if err := s.NotifyAssignee(u, s.forPolicyRecipient(p, u, n)); err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", u.Login, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// forPolicyRecipient готовит уведомление получателю, выбранному по RecipientPolicy.
+func (s *Notifier) forPolicyRecipient(p Participants, u GitHubUser, n Notification) Notification {
+	n = p.forRecipient(u, n)
+	n.excludeSelf = s.policy.ExcludeCommenter
+	return n
+}
+
+// NotifyAll шлёт уведомление всем участникам PR, не глядя на RecipientPolicy:
+// для событий жизненного цикла PR (влит, закрыт, готов к review) важны все.
+func (s *Notifier) NotifyAll(p Participants, n Notification) error {
+	everyone := RecipientPolicy{Author: true, Assignees: true, Reviewers: true}
+
+	var errs []error
+	for _, u := range everyone.Recipients(p) {
+		if err := s.NotifyAssignee(u, p.forRecipient(u, n)); err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", u.Login, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// bindingsFor находит привязки пользователя: по ID, а если его нет — по логину.
+// Заодно запоминает ID у привязок по логину и обновляет логин после переименования.
+func (s *Notifier) bindingsFor(u GitHubUser) ([]repository.UserBinding, error) {
+	bindings, err := s.users.GetByGitHubUser(u.ID, u.Login)
+	if err != nil {
+		return nil, err
+	}
+	login := normalizeLogin(u.Login)
+	if u.ID == 0 || login == "" {
+		return bindings, nil
+	}
+
+	for i, b := range bindings {
+		if b.GitHubUserID == u.ID && b.GitHubLogin == login {
+			continue
+		}
+		b.GitHubUserID = u.ID
+		b.GitHubLogin = login
+		if err := s.users.SaveBinding(b); err != nil {
+			return nil, fmt.Errorf("update binding %d: %w", b.TelegramID, err)
+		}
+		bindings[i] = b
+	}
+	return bindings, nil
+}
+
+// trustedBindings отбрасывает неподтверждённые привязки, если логин кем-то подтверждён
+// или если сервис настроен слать только подтверждённым.
+func (s *Notifier) trustedBindings(bindings []repository.UserBinding) []repository.UserBinding {
+	onlyVerified := s.verified
+	for _, b := range bindings {
+		if b.Verified {
+			onlyVerified = true
+			break
+		}
+	}
+	if !onlyVerified {
+		return bindings
+	}
 
+	out := bindings[:0:0]
 	for _, b := range bindings {
-		if err := s.sender.SendMessage(b.TelegramID, msg); err != nil {
-			return fmt.Errorf("send telegram message to %d: %w", b.TelegramID, err)
+		if b.Verified {
+			out = append(out, b)
 		}
 	}
+	return out
+}
+
+// deliver отправляет уведомление сразу, кладёт в дайджест или откладывает до конца тихих часов.
+func (s *Notifier) deliver(st repository.UserSettings, n Notification) error {
+	n = s.localize(st, n)
+	if s.digest != nil && st.DigestEnabled && !s.urgent[n.Kind] {
+		return s.addToDigest(st.TelegramID, n)
+	}
+
+	if s.held != nil && !s.urgent[n.Kind] {
+		q, err := quietHoursFrom(st)
+		if err != nil {
+			return fmt.Errorf("quiet hours for %d: %w", st.TelegramID, err)
+		}
+		if now := s.now(); q != nil && q.Contains(now) {
+			err := s.held.Hold(repository.HeldNotification{
+				TelegramID: st.TelegramID,
+				Text:       n.Text,
+				CreatedAt:  now,
+				ReleaseAt:  q.NextEnd(now),
+			})
+			if err != nil {
+				return fmt.Errorf("hold notification for %d: %w", st.TelegramID, err)
+			}
+			return nil
+		}
+	}
+
+	var err error
+	if rs, ok := s.sender.(RefSender); ok && n.PR.Repo != "" {
+		err = rs.SendMessageRef(st.TelegramID, n.Text, messageRef(n), append(n.Buttons, s.localizeButtons(st, actionButtons(n, true))...)...)
+	} else {
+		err = s.sender.SendMessage(st.TelegramID, n.Text, append(n.Buttons, s.localizeButtons(st, actionButtons(n, false))...)...)
+	}
+	if err != nil {
+		return fmt.Errorf("send telegram message to %d: %w", st.TelegramID, err)
+	}
 	return nil
 }
+
+// actionButtons — ряд кнопок под уведомлением о PR. Callback-кнопкам нужна запись
+// об отправленном сообщении (tracked), по ней обработчик находит PR; у issue их нет.
+func actionButtons(n Notification, tracked bool) [][]Button {
+	var row []Button
+	if n.PR.URL != "" {
+		row = append(row, Button{Key: "button.open", URL: n.PR.URL})
+	}
+	if tracked && n.PR.Number != 0 && !n.Issue {
+		row = append(row, Button{Key: "button.mute", Data: ActionData(ActionMutePrefix, n.PR)})
+		if n.Kind == EventReviewRequested {
+			row = append(row, Button{Key: "button.approve", Data: ActionData(ActionApprovePrefix, n.PR)})
+		}
+	}
+	if len(row) == 0 {
+		return nil
+	}
+	return [][]Button{row}
+}
+
+// muted сообщает, заглушил ли пользователь PR или весь его репозиторий.
+func (s *Notifier) muted(tgID int64, pr PullRequestRef) (bool, error) {
+	if s.mutes == nil || pr.Repo == "" {
+		return false, nil
+	}
+	mutes, err := s.mutes.ListMutes(tgID, s.now())
+	if err != nil {
+		return false, err
+	}
+	repo := strings.ToLower(pr.Repo)
+	for _, m := range mutes {
+		if m.Target == repo || (pr.Number != 0 && m.Target == muteTarget(pr)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func messageRef(n Notification) repository.MessageRef {
+	return repository.MessageRef{Repo: n.PR.Repo, Number: n.PR.Number, Kind: string(n.Kind), CommentID: n.CommentID}
+}
+
+func wantsEvent(st repository.UserSettings, kind EventKind) bool {
+	if kind == "" {
+		return true
+	}
+	for _, d := range st.DisabledEvents {
+		if d == string(kind) {
+			return false
+		}
+	}
+	return true
+}
+
+// wantsSelfNotify: выбор пользователя в /selfnotify важнее настроек бота.
+func (s *Notifier) wantsSelfNotify(st repository.UserSettings, n Notification) bool {
+	if st.SelfNotify != nil {
+		return *st.SelfNotify
+	}
+	return s.selfNotify && !n.excludeSelf
+}
+
+func quietHoursFrom(st repository.UserSettings) (*QuietHours, error) {
+	if st.QuietStart == nil || st.QuietEnd == nil {
+		return nil, nil
+	}
+	loc, err := loadLocation(st.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	return &QuietHours{Start: *st.QuietStart, End: *st.QuietEnd, Location: loc}, nil
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type senderMock struct {
+	sent map[int64][]string
+}
+
+func (s *senderMock) SendMessage(chatID int64, text string, _ ...[]Button) error {
+	if s.sent == nil {
+		s.sent = make(map[int64][]string)
+	}
+	s.sent[chatID] = append(s.sent[chatID], text)
+	return nil
+}
+
+func newTestNotifier(t *testing.T, cfg Config) (*Notifier, *senderMock) {
+	t.Helper()
+
+	users := memory.NewUserRepo()
+	for tgID, login := range map[int64]string{1: "author", 2: "reviewer"} {
+		if err := users.SaveBinding(repository.UserBinding{TelegramID: tgID, GitHubLogin: login}); err != nil {
+			t.Fatalf("SaveBinding: %v", err)
+		}
+	}
+
+	sender := &senderMock{}
+	return NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, sender, cfg), sender
+}
+
+func TestNotifier_SkipsSelfNotification(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+
+	if err := svc.NotifyAssignee(GitHubUser{Login: "Author"}, Notification{Actor: GitHubUser{Login: "author"}, Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 0 {
+		t.Fatalf("expected no messages, got %v", sender.sent[1])
+	}
+
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Actor: GitHubUser{Login: "reviewer"}, Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected 1 message, got %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_SelfNotifyPerUser(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+
+	if err := svc.SetSelfNotify(1, true); err != nil {
+		t.Fatalf("SetSelfNotify: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Actor: GitHubUser{Login: "author"}, Text: "echo"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected echo message, got %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_ExcludeCommenterIsSeparateFromSelfNotify(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{
+		Recipients: RecipientPolicy{Author: true, ExcludeCommenter: true},
+		SelfNotify: true,
+	})
+	p := Participants{Author: GitHubUser{Login: "author"}}
+	own := Notification{Actor: GitHubUser{Login: "author"}, Text: "own comment"}
+
+	// свой комментарий исключён политикой получателей, остальные свои действия приходят
+	if err := svc.NotifyParticipants(p, own); err != nil {
+		t.Fatalf("NotifyParticipants: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Actor: GitHubUser{Login: "author"}, Text: "self-assigned"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != "self-assigned" {
+		t.Fatalf("unexpected messages %v", sender.sent[1])
+	}
+
+	// явный /selfnotify on важнее exclude_commenter
+	if err := svc.SetSelfNotify(1, true); err != nil {
+		t.Fatalf("SetSelfNotify: %v", err)
+	}
+	if err := svc.NotifyParticipants(p, own); err != nil {
+		t.Fatalf("NotifyParticipants: %v", err)
+	}
+	if len(sender.sent[1]) != 2 || sender.sent[1][1] != "own comment" {
+		t.Fatalf("unexpected messages %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_SelfNotifyDefaultFromConfig(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{SelfNotify: true})
+
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Actor: GitHubUser{Login: "author"}, Text: "echo"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected echo message, got %v", sender.sent[1])
+	}
+
+	if err := svc.SetSelfNotify(1, false); err != nil {
+		t.Fatalf("SetSelfNotify: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Actor: GitHubUser{Login: "author"}, Text: "echo"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected no new messages, got %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_VerifiedBindingWins(t *testing.T) {
+	users := memory.NewUserRepo()
+	_ = users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "alice", Verified: true})
+	_ = users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "alice"})
+
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, sender, Config{})
+
+	if err := svc.NotifyAssignee(GitHubUser{Login: "alice"}, Notification{Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || len(sender.sent[2]) != 0 {
+		t.Fatalf("expected only verified binding to be notified, got %v", sender.sent)
+	}
+}
+
+func TestNotifier_RequireVerified(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{RequireVerified: true})
+
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no messages to unverified bindings, got %v", sender.sent)
+	}
+}
+
+func TestNotifier_MatchesByIDAndFollowsRename(t *testing.T) {
+	users := memory.NewUserRepo()
+	_ = users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "alice", GitHubUserID: 100, Verified: true})
+	_ = users.SaveBinding(repository.UserBinding{TelegramID: 2, GitHubLogin: "bob"})
+
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, sender, Config{})
+
+	// alice переименовалась в alice2 — находим по ID и обновляем логин
+	if err := svc.NotifyAssignee(GitHubUser{ID: 100, Login: "Alice2"}, Notification{Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected message by id, got %v", sender.sent)
+	}
+	if b, _ := users.GetByTelegramID(1); b.GitHubLogin != "alice2" {
+		t.Fatalf("expected login to be updated, got %q", b.GitHubLogin)
+	}
+
+	// привязка по логину без ID запоминает ID из webhook
+	if err := svc.NotifyAssignee(GitHubUser{ID: 200, Login: "bob"}, Notification{Text: "hi"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if b, _ := users.GetByTelegramID(2); b.GitHubUserID != 200 {
+		t.Fatalf("expected github user id to be stored, got %d", b.GitHubUserID)
+	}
+
+	// чужой аккаунт, занявший старый логин, не получает уведомления alice
+	if err := svc.NotifyAssignee(GitHubUser{ID: 300, Login: "alice2"}, Notification{Text: "not yours"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected no message for other account, got %v", sender.sent[1])
+	}
+}
+
+func TestNotifier_DisabledEventKindIsSkipped(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+
+	enabled, err := svc.ToggleEvent(1, EventReviewComment)
+	if err != nil {
+		t.Fatalf("ToggleEvent: %v", err)
+	}
+	if enabled {
+		t.Fatalf("expected review_comment to be disabled")
+	}
+
+	actor := GitHubUser{Login: "reviewer"}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Kind: EventReviewComment, Actor: actor, Text: "comment"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{Kind: EventApproved, Actor: actor, Text: "approved"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != "approved" {
+		t.Fatalf("expected only approved message, got %v", sender.sent[1])
+	}
+
+	prefs, err := svc.Preferences(1)
+	if err != nil {
+		t.Fatalf("Preferences: %v", err)
+	}
+	if prefs[EventReviewComment] || !prefs[EventApproved] {
+		t.Fatalf("unexpected preferences %v", prefs)
+	}
+
+	if enabled, err := svc.ToggleEvent(1, EventReviewComment); err != nil || !enabled {
+		t.Fatalf("expected review_comment to be enabled again, got %v, %v", enabled, err)
+	}
+}
+
+func TestNotifier_HoldsDuringQuietHoursAndFlushesBatch(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	sender := &senderMock{}
+	svc := NewNotifier(users, memory.NewSettingsRepo(), memory.NewHeldRepo(), nil, nil, sender, Config{Urgent: []EventKind{EventCIFailed}})
+
+	q, err := ParseQuietHours("22:00-08:00", "UTC")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+	if err := svc.SetQuietHours(1, &q); err != nil {
+		t.Fatalf("SetQuietHours: %v", err)
+	}
+
+	night := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return night }
+
+	actor := GitHubUser{Login: "reviewer"}
+	for _, n := range []Notification{
+		{Kind: EventApproved, Actor: actor, Text: "first"},
+		{Kind: EventReviewComment, Actor: actor, Text: "second"},
+		{Kind: EventCIFailed, Actor: actor, Text: "urgent"},
+	} {
+		if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != "urgent" {
+		t.Fatalf("expected only urgent message during quiet hours, got %v", sender.sent[1])
+	}
+
+	if err := svc.FlushHeld(night.Add(time.Hour)); err != nil {
+		t.Fatalf("FlushHeld: %v", err)
+	}
+	if len(sender.sent[1]) != 1 {
+		t.Fatalf("expected nothing flushed before window end, got %v", sender.sent[1])
+	}
+
+	if err := svc.FlushHeld(time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("FlushHeld: %v", err)
+	}
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("expected one batched message, got %v", sender.sent[1])
+	}
+	batch := sender.sent[1][1]
+	if !strings.Contains(batch, "first") || !strings.Contains(batch, "second") {
+		t.Fatalf("batch does not contain held notifications: %q", batch)
+	}
+
+	if err := svc.FlushHeld(time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("FlushHeld: %v", err)
+	}
+	if len(sender.sent[1]) != 2 {
+		t.Fatalf("held notifications must be flushed once, got %v", sender.sent[1])
+	}
+}
+
+func TestJoinMessages_SplitsLongBatches(t *testing.T) {
+	texts := []string{strings.Repeat("a", 30), strings.Repeat("b", 30), strings.Repeat("c", 30)}
+	got := joinMessages("header", texts, 70)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 parts, got %d: %q", len(got), got)
+	}
+	for _, part := range got {
+		if len(part) > 70 {
+			t.Fatalf("part is longer than limit: %q", part)
+		}
+	}
+}
+
+func TestNotifier_NotifyAllIgnoresPolicy(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{Recipients: RecipientPolicy{Author: true}})
+
+	p := Participants{
+		Author:    GitHubUser{Login: "author"},
+		Reviewers: []GitHubUser{{Login: "reviewer"}, {Login: "Reviewer"}},
+	}
+	if err := svc.NotifyAll(p, Notification{Kind: EventMerged, Actor: GitHubUser{Login: "someone"}, Text: "merged"}); err != nil {
+		t.Fatalf("NotifyAll: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || len(sender.sent[2]) != 1 {
+		t.Fatalf("expected one message each, got %v", sender.sent)
+	}
+}
+
+func TestNotifier_NotifyWithMentions(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{Recipients: RecipientPolicy{Author: true, Reviewers: true}})
+
+	p := Participants{Author: GitHubUser{Login: "author"}, Reviewers: []GitHubUser{{Login: "reviewer"}}}
+	n := Notification{Kind: EventIssueComment, Actor: GitHubUser{Login: "someone"}, Text: "comment"}
+	mention := Notification{Kind: EventMentioned, Actor: GitHubUser{Login: "someone"}, Text: "mention"}
+
+	if err := svc.NotifyWithMentions(p, []string{"Reviewer"}, n, mention); err != nil {
+		t.Fatalf("NotifyWithMentions: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != "comment" {
+		t.Fatalf("expected author to get the comment, got %v", sender.sent[1])
+	}
+	if len(sender.sent[2]) != 1 || sender.sent[2][0] != "mention" {
+		t.Fatalf("expected reviewer to get only the mention, got %v", sender.sent[2])
+	}
+}
+
+func TestNotifier_NotifyWithMentions_MentionsDisabled(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{Recipients: RecipientPolicy{Author: true, Reviewers: true}})
+	if _, err := svc.ToggleEvent(2, EventMentioned); err != nil {
+		t.Fatalf("ToggleEvent: %v", err)
+	}
+
+	p := Participants{Author: GitHubUser{Login: "author"}, Reviewers: []GitHubUser{{Login: "reviewer"}}}
+	n := Notification{Kind: EventIssueComment, Actor: GitHubUser{Login: "someone"}, Text: "comment"}
+	mention := Notification{Kind: EventMentioned, Actor: GitHubUser{Login: "someone"}, Text: "mention"}
+
+	if err := svc.NotifyWithMentions(p, []string{"reviewer"}, n, mention); err != nil {
+		t.Fatalf("NotifyWithMentions: %v", err)
+	}
+	// упоминания выключены — участник не должен остаться совсем без сообщения
+	if len(sender.sent[2]) != 1 || sender.sent[2][0] != "comment" {
+		t.Fatalf("expected reviewer to get the regular comment, got %v", sender.sent[2])
+	}
+}
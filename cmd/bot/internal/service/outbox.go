@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// RetryAfterError возвращается отправителем, когда Telegram просит подождать (HTTP 429).
+type RetryAfterError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("retry after %s: %v", e.RetryAfter, e.Err)
+}
+
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// PermanentError — ошибка, которую повтор не исправит: бот заблокирован (403),
+// чат не найден (400). Такое сообщение сразу уходит в dead letters.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// OutboxSender ставит сообщения в очередь вместо отправки в Telegram.
+type OutboxSender struct {
+	repo repository.OutboxRepository
+}
+
+func NewOutboxSender(repo repository.OutboxRepository) *OutboxSender {
+	return &OutboxSender{repo: repo}
+}
+
+func (s *OutboxSender) SendMessage(chatID int64, text string, buttons ...[]Button) error {
+	return s.repo.Enqueue(repository.OutboxMessage{ChatID: chatID, Text: text, Buttons: toOutboxButtons(buttons)})
+}
+
+func (s *OutboxSender) SendMessageRef(chatID int64, text string, ref repository.MessageRef, buttons ...[]Button) error {
+	return s.repo.Enqueue(repository.OutboxMessage{ChatID: chatID, Text: text, Buttons: toOutboxButtons(buttons), Ref: &ref})
+}
+
+func toOutboxButtons(rows [][]Button) [][]repository.OutboxButton {
+	if len(rows) == 0 {
+		return nil
+	}
+	out := make([][]repository.OutboxButton, 0, len(rows))
+	for _, row := range rows {
+		r := make([]repository.OutboxButton, 0, len(row))
+		for _, b := range row {
+			r = append(r, repository.OutboxButton{Text: b.Text, URL: b.URL, Data: b.Data})
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func fromOutboxButtons(rows [][]repository.OutboxButton) [][]Button {
+	if len(rows) == 0 {
+		return nil
+	}
+	out := make([][]Button, 0, len(rows))
+	for _, row := range rows {
+		r := make([]Button, 0, len(row))
+		for _, b := range row {
+			r = append(r, Button{Text: b.Text, URL: b.URL, Data: b.Data})
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+type OutboxConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// Lease — на сколько сообщение скрывается от других воркеров, пока идёт отправка.
+	Lease time.Duration
+}
+
+func (c OutboxConfig) withDefaults() OutboxConfig {
+	if c.Workers <= 0 {
+		c.Workers = 1
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 10
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 8
+	}
+	if c.BaseBackoff <= 0 {
+		c.BaseBackoff = 2 * time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 10 * time.Minute
+	}
+	if c.Lease <= 0 {
+		c.Lease = time.Minute
+	}
+	return c
+}
+
+// MessageIDSender — отправитель, который возвращает ID отправленного сообщения.
+type MessageIDSender interface {
+	SendMessageID(chatID int64, text string, buttons ...[]Button) (int, error)
+}
+
+// OutboxWorker разбирает очередь и отправляет сообщения через sender.
+// Если sender умеет вернуть ID сообщения, а у сообщения есть Ref, связь
+// сохраняется в messages.
+type OutboxWorker struct {
+	repo     repository.OutboxRepository
+	messages repository.MessageRepository
+	sender   TelegramSender
+	cfg      OutboxConfig
+	logger   *log.Logger
+	now      func() time.Time
+}
+
+// NewOutboxWorker создаёт воркер очереди. messages может быть nil — тогда
+// отправленные сообщения не запоминаются.
+func NewOutboxWorker(
+	repo repository.OutboxRepository,
+	messages repository.MessageRepository,
+	sender TelegramSender,
+	cfg OutboxConfig,
+	logger *log.Logger,
+) *OutboxWorker {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &OutboxWorker{
+		repo:     repo,
+		messages: messages,
+		sender:   sender,
+		cfg:      cfg.withDefaults(),
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Run запускает cfg.Workers воркеров и блокируется, пока ctx не будет отменён
+// и все воркеры не завершатся.
+func (w *OutboxWorker) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < w.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *OutboxWorker) loop(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := w.ProcessBatch()
+		if err != nil {
+			w.logger.Printf("[outbox] process batch error: %v", err)
+		}
+
+		// полная пачка — скорее всего в очереди есть ещё, не ждём
+		if n == w.cfg.BatchSize {
+			timer.Reset(0)
+		} else {
+			timer.Reset(w.cfg.PollInterval)
+		}
+	}
+}
+
+// ProcessBatch забирает одну пачку сообщений и пытается их отправить.
+func (w *OutboxWorker) ProcessBatch() (int, error) {
+	msgs, err := w.repo.ClaimDue(w.now(), w.cfg.Lease, w.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, m := range msgs {
+		if err := w.deliver(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(msgs), errors.Join(errs...)
+}
+
+func (w *OutboxWorker) deliver(m repository.OutboxMessage) error {
+	sendErr := w.send(m)
+	if sendErr == nil {
+		return w.repo.MarkSent(m.ID)
+	}
+
+	attempts := m.Attempts + 1
+	var permanent *PermanentError
+	if errors.As(sendErr, &permanent) {
+		w.logger.Printf("[outbox] message %d to %d dead-lettered, permanent error: %v", m.ID, m.ChatID, sendErr)
+		return w.repo.MoveToDeadLetter(m.ID, attempts, sendErr.Error())
+	}
+	if attempts >= w.cfg.MaxAttempts {
+		w.logger.Printf("[outbox] message %d to %d dead-lettered after %d attempts: %v", m.ID, m.ChatID, attempts, sendErr)
+		return w.repo.MoveToDeadLetter(m.ID, attempts, sendErr.Error())
+	}
+
+	delay := w.backoff(attempts)
+	var retryAfter *RetryAfterError
+	if errors.As(sendErr, &retryAfter) && retryAfter.RetryAfter > delay {
+		delay = retryAfter.RetryAfter
+	}
+
+	w.logger.Printf("[outbox] message %d to %d failed (attempt %d), retry in %s: %v", m.ID, m.ChatID, attempts, delay, sendErr)
+	return w.repo.Reschedule(m.ID, attempts, w.now().Add(delay), sendErr.Error())
+}
+
+func (w *OutboxWorker) send(m repository.OutboxMessage) error {
+	buttons := fromOutboxButtons(m.Buttons)
+	ids, ok := w.sender.(MessageIDSender)
+	if m.Ref == nil || w.messages == nil || !ok {
+		return w.sender.SendMessage(m.ChatID, m.Text, buttons...)
+	}
+
+	msgID, err := ids.SendMessageID(m.ChatID, m.Text, buttons...)
+	if err != nil {
+		return err
+	}
+	// сообщение уже ушло: ошибка здесь не повод отправлять его снова
+	sent := repository.SentMessage{ChatID: m.ChatID, MessageID: msgID, Ref: *m.Ref, Text: m.Text, SentAt: w.now()}
+	if err := w.messages.SaveSentMessage(sent); err != nil {
+		w.logger.Printf("[outbox] save sent message %d to %d: %v", msgID, m.ChatID, err)
+	}
+	w.strikeOutdated(m, msgID)
+	return nil
+}
+
+// strikeOutdated зачёркивает только что отправленное сообщение, если оно устарело, пока
+// ждало в очереди. Пометку перечитываем после сохранения отправленного: Annotator мог
+// поставить её уже после того, как воркер забрал сообщение.
+func (w *OutboxWorker) strikeOutdated(m repository.OutboxMessage, msgID int) {
+	editor, ok := w.sender.(MessageEditor)
+	if !ok {
+		return
+	}
+	note, err := w.repo.GetNote(m.ID)
+	if err != nil {
+		w.logger.Printf("[outbox] get note of message %d: %v", m.ID, err)
+		return
+	}
+	if note == "" {
+		return
+	}
+	// Annotator мог уже зачеркнуть сообщение, найдя его среди отправленных. Если запись
+	// не сохранилась, Annotator его не видит и зачёркивает только воркер.
+	at := w.now()
+	marked, err := w.messages.MarkSentMessageEdited(m.ChatID, msgID, at)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		marked = true
+	case err != nil:
+		w.logger.Printf("[outbox] mark message %d in %d edited: %v", msgID, m.ChatID, err)
+		return
+	}
+	if !marked {
+		return
+	}
+	if err := editor.StrikeMessage(m.ChatID, msgID, strikeText(m.Text, note), note); err != nil {
+		w.logger.Printf("[outbox] strike message %d in %d: %v", msgID, m.ChatID, err)
+		if err := w.messages.UnmarkSentMessageEdited(m.ChatID, msgID, at); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			w.logger.Printf("[outbox] unmark message %d in %d: %v", msgID, m.ChatID, err)
+		}
+	}
+}
+
+func (w *OutboxWorker) backoff(attempts int) time.Duration {
+	d := w.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= w.cfg.MaxBackoff {
+			return w.cfg.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type failingSender struct {
+	err   error
+	calls int
+}
+
+func (s *failingSender) SendMessage(chatID int64, text string, _ ...[]Button) error {
+	s.calls++
+	return s.err
+}
+
+func newTestWorker(repo repository.OutboxRepository, sender TelegramSender, now time.Time) *OutboxWorker {
+	w := NewOutboxWorker(repo, nil, sender, OutboxConfig{
+		BatchSize:   10,
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  time.Minute,
+	}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return now }
+	return w
+}
+
+func TestOutboxWorker_SendsAndRemoves(t *testing.T) {
+	repo := memory.NewOutboxRepo()
+	sender := &senderMock{}
+
+	if err := NewOutboxSender(repo).SendMessage(42, "hello"); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	now := time.Now()
+	w := newTestWorker(repo, sender, now)
+
+	n, err := w.ProcessBatch()
+	if err != nil || n != 1 {
+		t.Fatalf("ProcessBatch: n=%d err=%v", n, err)
+	}
+	if len(sender.sent[42]) != 1 {
+		t.Fatalf("expected message to be sent, got %v", sender.sent)
+	}
+
+	left, _ := repo.ClaimDue(now.Add(time.Hour), time.Minute, 10)
+	if len(left) != 0 {
+		t.Fatalf("expected empty outbox, got %d", len(left))
+	}
+}
+
+func TestOutboxWorker_RetriesWithBackoffAndDeadLetters(t *testing.T) {
+	repo := memory.NewOutboxRepo()
+	sender := &failingSender{err: errors.New("boom")}
+	now := time.Now()
+
+	_ = repo.Enqueue(repository.OutboxMessage{ChatID: 1, Text: "x", NextAttemptAt: now})
+
+	// попытка 1 — ретрай через BaseBackoff
+	w := newTestWorker(repo, sender, now)
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+	if due, _ := repo.ClaimDue(now.Add(999*time.Millisecond), 0, 10); len(due) != 0 {
+		t.Fatalf("message should not be due before backoff")
+	}
+
+	// попытка 2 — ретрай через 2*BaseBackoff
+	now = now.Add(time.Second)
+	w = newTestWorker(repo, sender, now)
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	// попытка 3 — dead letter
+	now = now.Add(2 * time.Second)
+	w = newTestWorker(repo, sender, now)
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	if sender.calls != 3 {
+		t.Fatalf("expected 3 send attempts, got %d", sender.calls)
+	}
+	dead, _ := repo.ListDeadLetters(10)
+	if len(dead) != 1 || dead[0].Attempts != 3 || dead[0].LastError != "boom" {
+		t.Fatalf("unexpected dead letters: %+v", dead)
+	}
+}
+
+func TestOutboxWorker_DeadLettersPermanentErrorAtOnce(t *testing.T) {
+	repo := memory.NewOutboxRepo()
+	sender := &failingSender{err: &PermanentError{Err: errors.New("Forbidden: bot was blocked by the user")}}
+	now := time.Now()
+
+	_ = repo.Enqueue(repository.OutboxMessage{ChatID: 1, Text: "x", NextAttemptAt: now})
+
+	w := newTestWorker(repo, sender, now)
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	if sender.calls != 1 {
+		t.Fatalf("expected a single send attempt, got %d", sender.calls)
+	}
+	dead, _ := repo.ListDeadLetters(10)
+	if len(dead) != 1 || dead[0].Attempts != 1 {
+		t.Fatalf("expected message to be dead-lettered after one attempt, got %+v", dead)
+	}
+	if due, _ := repo.ClaimDue(now.Add(time.Hour), 0, 10); len(due) != 0 {
+		t.Fatalf("permanent failure should not be retried")
+	}
+}
+
+func TestOutboxWorker_RespectsRetryAfter(t *testing.T) {
+	repo := memory.NewOutboxRepo()
+	sender := &failingSender{err: &RetryAfterError{RetryAfter: 30 * time.Second, Err: errors.New("429")}}
+	now := time.Now()
+
+	_ = repo.Enqueue(repository.OutboxMessage{ChatID: 1, Text: "x", NextAttemptAt: now})
+
+	w := newTestWorker(repo, sender, now)
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	if due, _ := repo.ClaimDue(now.Add(29*time.Second), 0, 10); len(due) != 0 {
+		t.Fatalf("message should wait for retry_after")
+	}
+	if due, _ := repo.ClaimDue(now.Add(30*time.Second), 0, 10); len(due) != 1 {
+		t.Fatalf("message should be due after retry_after")
+	}
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// PullRequestTracker ведёт состояние PR по webhook-событиям и отвечает на запросы по нему.
+type PullRequestTracker struct {
+	mu    sync.Mutex // события одного PR обрабатываются параллельно разными воркерами
+	prs   repository.PullRequestRepository
+	users repository.UserRepository
+	now   func() time.Time
+}
+
+func NewPullRequestTracker(prs repository.PullRequestRepository, users repository.UserRepository) *PullRequestTracker {
+	return &PullRequestTracker{prs: prs, users: users, now: time.Now}
+}
+
+// ReviewRequest — открытый PR, который ждёт review пользователя.
+type ReviewRequest struct {
+	Ref         PullRequestRef
+	Author      string
+	RequestedAt time.Time
+}
+
+// PendingReviews возвращает PR, где пользователь — запрошенный ревьюер, самые давние первыми.
+// Черновики не учитываются: их ещё рано смотреть.
+func (t *PullRequestTracker) PendingReviews(tgID int64) ([]ReviewRequest, error) {
+	b, err := t.users.GetByTelegramID(tgID)
+	if err != nil {
+		return nil, err
+	}
+	me := GitHubUser{ID: b.GitHubUserID, Login: b.GitHubLogin}
+
+	prs, err := t.prs.ListReviewRequests(me.ID, me.Login)
+	if err != nil {
+		return nil, fmt.Errorf("list review requests: %w", err)
+	}
+
+	out := make([]ReviewRequest, 0, len(prs))
+	for _, pr := range prs {
+		if pr.Draft {
+			continue
+		}
+		requestedAt := pr.CreatedAt
+		for _, rv := range pr.Reviewers {
+			if rv.Requested && reviewerIs(rv, me) && !rv.RequestedAt.IsZero() {
+				requestedAt = rv.RequestedAt
+				break
+			}
+		}
+		out = append(out, ReviewRequest{Ref: refOf(pr), Author: pr.AuthorLogin, RequestedAt: requestedAt})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].RequestedAt.Before(out[j].RequestedAt) })
+	return out, nil
+}
+
+// Reviewers возвращает всех известных ревьюеров PR: запрошенных сейчас и уже оставивших review.
+func (t *PullRequestTracker) Reviewers(repo string, number int) ([]GitHubUser, error) {
+	pr, err := t.prs.GetPullRequest(strings.ToLower(repo), number)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get pull request %s#%d: %w", repo, number, err)
+	}
+
+	out := make([]GitHubUser, 0, len(pr.Reviewers))
+	for _, rv := range pr.Reviewers {
+		if rv.Requested || rv.State != "" {
+			out = append(out, GitHubUser{ID: rv.UserID, Login: rv.Login})
+		}
+	}
+	return out, nil
+}
+
+// TrackPullRequest применяет снимок PR из pull_request или review-событий.
+func (t *PullRequestTracker) TrackPullRequest(s PullRequestSnapshot) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pr, err := t.load(s)
+	if err != nil {
+		return err
+	}
+	t.apply(pr, s)
+	return t.save(pr)
+}
+
+// TrackReview применяет снимок PR и запоминает review от reviewer.
+// state — approved, changes_requested, commented или dismissed.
+func (t *PullRequestTracker) TrackReview(s PullRequestSnapshot, reviewer GitHubUser, state string, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pr, err := t.load(s)
+	if err != nil {
+		return err
+	}
+	t.apply(pr, s)
+	if at.IsZero() {
+		at = t.now()
+	}
+	setReview(pr, reviewer, strings.ToLower(state), at)
+	return t.save(pr)
+}
+
+// Состояния ревьюера в MyPullRequests, помимо approved, changes_requested и commented.
+const ReviewPending = "pending"
+
+// ReviewerStatus — последнее состояние review от одного ревьюера.
+type ReviewerStatus struct {
+	Login string
+	State string // approved, changes_requested, commented или ReviewPending
+}
+
+// AuthoredPullRequest — открытый PR пользователя со статусом review.
+type AuthoredPullRequest struct {
+	Ref       PullRequestRef
+	Draft     bool
+	Mergeable *bool // nil — GitHub ещё не сообщил
+	Reviewers []ReviewerStatus
+}
+
+// MyPullRequests возвращает открытые PR пользователя со статусом каждого ревьюера.
+// Данные берутся из сохранённого состояния, без обращения к GitHub.
+func (t *PullRequestTracker) MyPullRequests(tgID int64) ([]AuthoredPullRequest, error) {
+	b, err := t.users.GetByTelegramID(tgID)
+	if err != nil {
+		return nil, err
+	}
+
+	prs, err := t.prs.ListAuthoredPullRequests(b.GitHubUserID, b.GitHubLogin)
+	if err != nil {
+		return nil, fmt.Errorf("list authored pull requests: %w", err)
+	}
+
+	out := make([]AuthoredPullRequest, 0, len(prs))
+	for _, pr := range prs {
+		item := AuthoredPullRequest{Ref: refOf(pr), Draft: pr.Draft, Mergeable: pr.Mergeable}
+		for _, rv := range pr.Reviewers {
+			// повторный запрос review важнее прошлого ответа
+			state := rv.State
+			if rv.Requested {
+				state = ReviewPending
+			}
+			if state == "" {
+				continue
+			}
+			item.Reviewers = append(item.Reviewers, ReviewerStatus{Login: rv.Login, State: state})
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func (t *PullRequestTracker) load(s PullRequestSnapshot) (*repository.PullRequest, error) {
+	if s.Ref.Repo == "" || s.Ref.Number == 0 {
+		return nil, fmt.Errorf("pull request without repo or number")
+	}
+
+	pr, err := t.prs.GetPullRequest(s.Ref.Repo, s.Ref.Number)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &repository.PullRequest{Repo: strings.ToLower(s.Ref.Repo), Number: s.Ref.Number}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get pull request %s#%d: %w", s.Ref.Repo, s.Ref.Number, err)
+	}
+	return pr, nil
+}
+
+func (t *PullRequestTracker) save(pr *repository.PullRequest) error {
+	if err := t.prs.SavePullRequest(*pr); err != nil {
+		return fmt.Errorf("save pull request %s#%d: %w", pr.Repo, pr.Number, err)
+	}
+	return nil
+}
+
+// apply переносит снимок в сохранённое состояние. Снимок старше сохранённого
+// пропускается, чтобы события, обработанные не по порядку, не откатывали состояние.
+func (t *PullRequestTracker) apply(pr *repository.PullRequest, s PullRequestSnapshot) {
+	if !pr.UpdatedAt.IsZero() && s.UpdatedAt.Before(pr.UpdatedAt) {
+		return
+	}
+
+	pr.GitHubID = s.ID
+	pr.Title = s.Ref.Title
+	pr.URL = s.Ref.URL
+	pr.AuthorLogin = normalizeLogin(s.Author.Login)
+	pr.AuthorID = s.Author.ID
+	pr.Draft = s.Draft
+	// mergeable GitHub считает лениво и часто присылает null: храним последнее
+	// известное значение, пока не сменился head
+	if s.HeadSHA != pr.HeadSHA {
+		pr.Mergeable = nil
+	}
+	if s.Mergeable != nil {
+		pr.Mergeable = s.Mergeable
+	}
+	pr.HeadSHA = s.HeadSHA
+	pr.CreatedAt = s.CreatedAt
+	pr.UpdatedAt = s.UpdatedAt
+
+	switch {
+	case s.Merged:
+		pr.State = repository.PRStateMerged
+	case s.State == repository.PRStateClosed:
+		pr.State = repository.PRStateClosed
+	default:
+		pr.State = repository.PRStateOpen
+	}
+
+	pr.Assignees, pr.AssigneeIDs = pr.Assignees[:0], pr.AssigneeIDs[:0]
+	for _, u := range s.Assignees {
+		pr.Assignees = append(pr.Assignees, normalizeLogin(u.Login))
+		pr.AssigneeIDs = append(pr.AssigneeIDs, u.ID)
+	}
+
+	requestedAt := s.UpdatedAt
+	if requestedAt.IsZero() {
+		requestedAt = t.now()
+	}
+
+	requested := make([]bool, len(s.Reviewers))
+	for i := range pr.Reviewers {
+		rv := &pr.Reviewers[i]
+		idx := indexOfUser(s.Reviewers, rv)
+		if idx < 0 {
+			rv.Requested = false
+			continue
+		}
+		requested[idx] = true
+		if !rv.Requested {
+			rv.Requested = true
+			rv.RequestedAt = requestedAt
+		}
+		if s.Reviewers[idx].ID != 0 {
+			rv.UserID = s.Reviewers[idx].ID
+		}
+		rv.Login = normalizeLogin(s.Reviewers[idx].Login)
+	}
+	for i, u := range s.Reviewers {
+		if requested[i] {
+			continue
+		}
+		pr.Reviewers = append(pr.Reviewers, repository.PullRequestReviewer{
+			Login:       normalizeLogin(u.Login),
+			UserID:      u.ID,
+			Requested:   true,
+			RequestedAt: requestedAt,
+		})
+	}
+}
+
+func setReview(pr *repository.PullRequest, reviewer GitHubUser, state string, at time.Time) {
+	var rv *repository.PullRequestReviewer
+	for i := range pr.Reviewers {
+		if reviewerIs(pr.Reviewers[i], reviewer) {
+			rv = &pr.Reviewers[i]
+			break
+		}
+	}
+	if rv == nil {
+		pr.Reviewers = append(pr.Reviewers, repository.PullRequestReviewer{
+			Login:  normalizeLogin(reviewer.Login),
+			UserID: reviewer.ID,
+		})
+		rv = &pr.Reviewers[len(pr.Reviewers)-1]
+	}
+	// ревьюер мог переименоваться с прошлого события
+	if reviewer.ID != 0 {
+		rv.UserID = reviewer.ID
+	}
+	if reviewer.Login != "" {
+		rv.Login = normalizeLogin(reviewer.Login)
+	}
+
+	// GitHub снимает запрос review, как только ревьюер его оставил
+	rv.Requested = false
+	rv.ReviewedAt = at
+	switch state {
+	case "dismissed":
+		rv.State = ""
+	case "commented":
+		// комментарий не отменяет approve или запрос изменений
+		if rv.State == "" {
+			rv.State = state
+		}
+	default:
+		rv.State = state
+	}
+}
+
+func refOf(pr repository.PullRequest) PullRequestRef {
+	return PullRequestRef{Repo: pr.Repo, Number: pr.Number, Title: pr.Title, URL: pr.URL}
+}
+
+func indexOfUser(users []GitHubUser, rv *repository.PullRequestReviewer) int {
+	for i, u := range users {
+		if reviewerIs(*rv, u) {
+			return i
+		}
+	}
+	return -1
+}
+
+func reviewerIs(rv repository.PullRequestReviewer, u GitHubUser) bool {
+	return GitHubUser{ID: rv.UserID, Login: rv.Login}.Same(u)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+func testSnapshot(updated time.Time, reviewers ...GitHubUser) PullRequestSnapshot {
+	return PullRequestSnapshot{
+		ID:        100,
+		Ref:       PullRequestRef{Repo: "Org/Repo", Number: 7, Title: "Fix", URL: "https://github.com/org/repo/pull/7"},
+		Author:    GitHubUser{ID: 1, Login: "Author"},
+		Assignees: []GitHubUser{{ID: 2, Login: "owner"}},
+		Reviewers: reviewers,
+		State:     "open",
+		HeadSHA:   "abc",
+		CreatedAt: updated.Add(-time.Hour),
+		UpdatedAt: updated,
+	}
+}
+
+func TestPullRequestTracker_ReviewLifecycle(t *testing.T) {
+	repo := memory.NewPullRequestRepo()
+	tracker := NewPullRequestTracker(repo, memory.NewUserRepo())
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	alice := GitHubUser{ID: 10, Login: "alice"}
+	bob := GitHubUser{ID: 11, Login: "bob"}
+
+	if err := tracker.TrackPullRequest(testSnapshot(t0, alice, bob)); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+
+	// alice одобрила — GitHub убирает её из requested_reviewers
+	if err := tracker.TrackReview(testSnapshot(t0.Add(time.Hour), bob), alice, "APPROVED", t0.Add(time.Hour)); err != nil {
+		t.Fatalf("TrackReview: %v", err)
+	}
+	// запоздавшее событие со старым снимком не должно вернуть alice в запрошенные
+	if err := tracker.TrackPullRequest(testSnapshot(t0.Add(time.Minute), alice, bob)); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+	// комментарий не отменяет approve
+	if err := tracker.TrackReview(testSnapshot(t0.Add(2*time.Hour), bob), alice, "commented", t0.Add(2*time.Hour)); err != nil {
+		t.Fatalf("TrackReview: %v", err)
+	}
+
+	pr, err := repo.GetPullRequest("org/repo", 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest: %v", err)
+	}
+	if pr.AuthorLogin != "author" || pr.State != repository.PRStateOpen || pr.HeadSHA != "abc" || len(pr.Assignees) != 1 {
+		t.Fatalf("unexpected pull request %+v", pr)
+	}
+	if len(pr.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %+v", pr.Reviewers)
+	}
+	for _, rv := range pr.Reviewers {
+		switch rv.Login {
+		case "alice":
+			if rv.Requested || rv.State != "approved" {
+				t.Fatalf("unexpected alice state %+v", rv)
+			}
+		case "bob":
+			if !rv.Requested || rv.State != "" || !rv.RequestedAt.Equal(t0) {
+				t.Fatalf("unexpected bob state %+v", rv)
+			}
+		default:
+			t.Fatalf("unexpected reviewer %+v", rv)
+		}
+	}
+
+	merged := testSnapshot(t0.Add(3 * time.Hour))
+	merged.State = "closed"
+	merged.Merged = true
+	if err := tracker.TrackPullRequest(merged); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+	pr, err = repo.GetPullRequest("org/repo", 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest: %v", err)
+	}
+	if pr.State != repository.PRStateMerged {
+		t.Fatalf("expected merged state, got %q", pr.State)
+	}
+	if open, _ := repo.ListOpenPullRequests(); len(open) != 0 {
+		t.Fatalf("merged PR must not be listed as open: %+v", open)
+	}
+}
+
+func TestPullRequestTracker_PendingReviews(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "alice", GitHubUserID: 10}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	tracker := NewPullRequestTracker(memory.NewPullRequestRepo(), users)
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	alice := GitHubUser{ID: 10, Login: "alice"}
+
+	older := testSnapshot(t0, alice)
+	older.Ref.Number = 1
+	newer := testSnapshot(t0.Add(time.Hour), alice)
+	newer.Ref.Number = 2
+	draft := testSnapshot(t0, alice)
+	draft.Ref.Number = 3
+	draft.Draft = true
+	reviewed := testSnapshot(t0, alice)
+	reviewed.Ref.Number = 4
+
+	for _, s := range []PullRequestSnapshot{newer, older, draft, reviewed} {
+		if err := tracker.TrackPullRequest(s); err != nil {
+			t.Fatalf("TrackPullRequest: %v", err)
+		}
+	}
+	reviewed.Reviewers = nil
+	reviewed.UpdatedAt = t0.Add(time.Minute)
+	if err := tracker.TrackReview(reviewed, alice, "approved", reviewed.UpdatedAt); err != nil {
+		t.Fatalf("TrackReview: %v", err)
+	}
+
+	got, err := tracker.PendingReviews(1)
+	if err != nil {
+		t.Fatalf("PendingReviews: %v", err)
+	}
+	if len(got) != 2 || got[0].Ref.Number != 1 || got[1].Ref.Number != 2 {
+		t.Fatalf("expected PRs 1 and 2, oldest first, got %+v", got)
+	}
+	if !got[0].RequestedAt.Equal(t0) || got[0].Author != "author" {
+		t.Fatalf("unexpected review request %+v", got[0])
+	}
+
+	if _, err := tracker.PendingReviews(2); err == nil {
+		t.Fatalf("expected error for user without binding")
+	}
+}
+
+func TestPullRequestTracker_MyPullRequests(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author", GitHubUserID: 1}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	tracker := NewPullRequestTracker(memory.NewPullRequestRepo(), users)
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	alice := GitHubUser{ID: 10, Login: "alice"}
+	bob := GitHubUser{ID: 11, Login: "bob"}
+	mergeable := true
+
+	s := testSnapshot(t0, alice, bob)
+	s.Mergeable = &mergeable
+	if err := tracker.TrackPullRequest(s); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+	// следующий payload без mergeable не должен стирать известное значение
+	if err := tracker.TrackReview(testSnapshot(t0.Add(time.Hour), bob), alice, "changes_requested", t0.Add(time.Hour)); err != nil {
+		t.Fatalf("TrackReview: %v", err)
+	}
+
+	got, err := tracker.MyPullRequests(1)
+	if err != nil {
+		t.Fatalf("MyPullRequests: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 pull request, got %+v", got)
+	}
+	pr := got[0]
+	if pr.Mergeable == nil || !*pr.Mergeable {
+		t.Fatalf("expected mergeable=true, got %v", pr.Mergeable)
+	}
+	want := map[string]string{"alice": "changes_requested", "bob": ReviewPending}
+	if len(pr.Reviewers) != len(want) {
+		t.Fatalf("unexpected reviewers %+v", pr.Reviewers)
+	}
+	for _, rv := range pr.Reviewers {
+		if want[rv.Login] != rv.State {
+			t.Fatalf("reviewer %s: got %q, want %q", rv.Login, rv.State, want[rv.Login])
+		}
+	}
+
+	// новый коммит — mergeable снова неизвестен
+	pushed := testSnapshot(t0.Add(2*time.Hour), bob)
+	pushed.HeadSHA = "def"
+	if err := tracker.TrackPullRequest(pushed); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+	got, err = tracker.MyPullRequests(1)
+	if err != nil {
+		t.Fatalf("MyPullRequests: %v", err)
+	}
+	if got[0].Mergeable != nil {
+		t.Fatalf("expected unknown mergeable after push, got %v", *got[0].Mergeable)
+	}
+}
+
+func TestPullRequestTracker_ReviewerRenameKeepsState(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "alice-new", GitHubUserID: 10}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	repo := memory.NewPullRequestRepo()
+	tracker := NewPullRequestTracker(repo, users)
+
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := tracker.TrackPullRequest(testSnapshot(t0, GitHubUser{ID: 10, Login: "alice"})); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+	// alice переименовалась в GitHub: ID тот же, логин новый
+	renamed := GitHubUser{ID: 10, Login: "Alice-New"}
+	if err := tracker.TrackPullRequest(testSnapshot(t0.Add(time.Hour), renamed)); err != nil {
+		t.Fatalf("TrackPullRequest: %v", err)
+	}
+
+	pr, err := repo.GetPullRequest("org/repo", 7)
+	if err != nil {
+		t.Fatalf("GetPullRequest: %v", err)
+	}
+	if len(pr.Reviewers) != 1 || pr.Reviewers[0].Login != "alice-new" || !pr.Reviewers[0].RequestedAt.Equal(t0) {
+		t.Fatalf("expected one renamed reviewer requested at %v, got %+v", t0, pr.Reviewers)
+	}
+	if len(pr.AssigneeIDs) != 1 || pr.AssigneeIDs[0] != 2 {
+		t.Fatalf("expected assignee IDs to be stored, got %+v", pr.AssigneeIDs)
+	}
+
+	got, err := tracker.PendingReviews(1)
+	if err != nil {
+		t.Fatalf("PendingReviews: %v", err)
+	}
+	if len(got) != 1 || !got[0].RequestedAt.Equal(t0) {
+		t.Fatalf("expected pending review after rename, got %+v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrBadClock — время не в формате HH:MM.
+	ErrBadClock = errors.New("time must be HH:MM")
+	// ErrBadQuietWindow — окно тишины не вида HH:MM-HH:MM или пустое.
+	ErrBadQuietWindow = errors.New("quiet window must be HH:MM-HH:MM with different ends")
+	// ErrBadTimezone — неизвестное IANA-имя часового пояса.
+	ErrBadTimezone = errors.New("unknown timezone")
+)
+
+// QuietHours — окно тишины в часовом поясе пользователя.
+// Start > End означает окно через полночь (22:00-08:00).
+type QuietHours struct {
+	Start    int // минуты от полуночи
+	End      int
+	Location *time.Location
+}
+
+// ParseQuietHours разбирает окно вида "22:00-08:00" и IANA-имя часового пояса.
+// Пустой tz означает UTC.
+func ParseQuietHours(window, tz string) (QuietHours, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(window), "-")
+	if !ok {
+		return QuietHours{}, fmt.Errorf("%w, got %q", ErrBadQuietWindow, window)
+	}
+	start, err := ParseClock(from)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := ParseClock(to)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	if start == end {
+		return QuietHours{}, fmt.Errorf("%w, got %q", ErrBadQuietWindow, window)
+	}
+
+	loc, err := loadLocation(tz)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	return QuietHours{Start: start, End: end, Location: loc}, nil
+}
+
+// Contains сообщает, попадает ли момент t в окно тишины.
+func (q QuietHours) Contains(t time.Time) bool {
+	local := t.In(q.location())
+	m := local.Hour()*60 + local.Minute()
+	if q.Start < q.End {
+		return m >= q.Start && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+// NextEnd возвращает ближайший после t момент окончания окна.
+func (q QuietHours) NextEnd(t time.Time) time.Time {
+	local := t.In(q.location())
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.End/60, q.End%60, 0, 0, q.location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+func (q QuietHours) String() string {
+	return fmt.Sprintf("%s-%s %s", formatClock(q.Start), formatClock(q.End), q.location())
+}
+
+func (q QuietHours) location() *time.Location {
+	if q.Location == nil {
+		return time.UTC
+	}
+	return q.Location
+}
+
+func loadLocation(tz string) (*time.Location, error) {
+	tz = strings.TrimSpace(tz)
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrBadTimezone, tz)
+	}
+	return loc, nil
+}
+
+// ParseClock разбирает время "HH:MM" в минуты от полуночи.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%w, got %q", ErrBadClock, s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuietHours_ContainsAndNextEnd(t *testing.T) {
+	q, err := ParseQuietHours("22:00-08:00", "Europe/Berlin")
+	if err != nil {
+		t.Fatalf("ParseQuietHours: %v", err)
+	}
+	berlin := q.Location
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		contains bool
+		nextEnd  time.Time
+	}{
+		{
+			name:     "before midnight",
+			at:       time.Date(2024, 3, 1, 23, 30, 0, 0, berlin),
+			contains: true,
+			nextEnd:  time.Date(2024, 3, 2, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "after midnight",
+			at:       time.Date(2024, 3, 2, 3, 0, 0, 0, berlin),
+			contains: true,
+			nextEnd:  time.Date(2024, 3, 2, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "daytime",
+			at:       time.Date(2024, 3, 2, 12, 0, 0, 0, berlin),
+			contains: false,
+			nextEnd:  time.Date(2024, 3, 3, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:     "end is exclusive",
+			at:       time.Date(2024, 3, 2, 8, 0, 0, 0, berlin),
+			contains: false,
+		},
+		{
+			name:     "utc instant",
+			at:       time.Date(2024, 3, 1, 21, 30, 0, 0, time.UTC), // 22:30 в Берлине
+			contains: true,
+			nextEnd:  time.Date(2024, 3, 2, 8, 0, 0, 0, berlin),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := q.Contains(tt.at); got != tt.contains {
+				t.Fatalf("Contains = %v, want %v", got, tt.contains)
+			}
+			if !tt.nextEnd.IsZero() && !q.NextEnd(tt.at).Equal(tt.nextEnd) {
+				t.Fatalf("NextEnd = %v, want %v", q.NextEnd(tt.at), tt.nextEnd)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		window, tz string
+		want       error
+	}{
+		{"22:00", "", ErrBadQuietWindow},
+		{"25:00-08:00", "", ErrBadClock},
+		{"08:00-08:00", "", ErrBadQuietWindow},
+		{"22:00-08:00", "Mars/Olympus", ErrBadTimezone},
+	} {
+		if _, err := ParseQuietHours(tc.window, tc.tz); !errors.Is(err, tc.want) {
+			t.Fatalf("ParseQuietHours(%q, %q) = %v, want %v", tc.window, tc.tz, err, tc.want)
+		}
+	}
+}
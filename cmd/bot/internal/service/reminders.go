@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// ReminderSnoozePrefix — префикс callback-данных кнопки «Отложить» у напоминания.
+const ReminderSnoozePrefix = "remind:snooze:"
+
+// DefaultReminderSnooze — на сколько «Отложить» сдвигает напоминание, если в конфиге не задано.
+const DefaultReminderSnooze = 2 * time.Hour
+
+// ReminderRule — пороги напоминаний в рабочем времени. Нулевой порог выключает шаг.
+type ReminderRule struct {
+	RemindAfter   time.Duration // напомнить ревьюеру
+	EscalateAfter time.Duration // сообщить автору PR и в чат лида
+	LeadChatID    int64         // 0 — без чата лида
+}
+
+type ReminderConfig struct {
+	Default  ReminderRule
+	Repos    map[string]ReminderRule // owner/name -> правило вместо Default
+	Calendar WorkCalendar
+	Snooze   time.Duration // на сколько откладывает кнопка «Отложить»
+}
+
+// Reminders напоминает о запрошенных review, которые долго ждут ответа.
+type Reminders struct {
+	prs       repository.PullRequestRepository
+	reminders repository.ReminderRepository
+	notifier  *Notifier
+	sender    TelegramSender
+	cfg       ReminderConfig
+}
+
+func NewReminders(
+	prs repository.PullRequestRepository,
+	reminders repository.ReminderRepository,
+	notifier *Notifier,
+	sender TelegramSender,
+	cfg ReminderConfig,
+) *Reminders {
+	repos := make(map[string]ReminderRule, len(cfg.Repos))
+	for name, rule := range cfg.Repos {
+		repos[strings.ToLower(strings.TrimSpace(name))] = rule
+	}
+	cfg.Repos = repos
+	if cfg.Snooze <= 0 {
+		cfg.Snooze = DefaultReminderSnooze
+	}
+	return &Reminders{prs: prs, reminders: reminders, notifier: notifier, sender: sender, cfg: cfg}
+}
+
+// Check рассылает созревшие напоминания и эскалации. Вне рабочего времени ничего не шлёт:
+// напоминание ночью или в выходной никому не поможет.
+func (r *Reminders) Check(now time.Time) error {
+	if !r.cfg.Calendar.IsWorkingTime(now) {
+		return nil
+	}
+
+	prs, err := r.prs.ListOpenPullRequests()
+	if err != nil {
+		return fmt.Errorf("list open pull requests: %w", err)
+	}
+
+	var errs []error
+	for _, pr := range prs {
+		if pr.Draft {
+			continue
+		}
+		rule := r.rule(pr.Repo)
+		if rule.RemindAfter <= 0 && rule.EscalateAfter <= 0 {
+			continue
+		}
+		for _, rv := range pr.Reviewers {
+			if !rv.Requested || rv.RequestedAt.IsZero() {
+				continue
+			}
+			if err := r.check(pr, rv, rule, now); err != nil {
+				errs = append(errs, fmt.Errorf("reminder %s#%d for %s: %w", pr.Repo, pr.Number, rv.Login, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reminders) check(pr repository.PullRequest, rv repository.PullRequestReviewer, rule ReminderRule, now time.Time) error {
+	rem, err := r.reminders.GetReminder(pr.Repo, pr.Number, rv.UserID, rv.Login)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		rem = &repository.ReviewReminder{Repo: pr.Repo, Number: pr.Number}
+	case err != nil:
+		return err
+	}
+	// после переименования в GitHub запись находится по ID, логин в ней обновляется
+	renamed := rem.ID != 0 && (rem.Reviewer != rv.Login || (rv.UserID != 0 && rem.ReviewerID != rv.UserID))
+	rem.Reviewer = rv.Login
+	if rv.UserID != 0 {
+		rem.ReviewerID = rv.UserID
+	}
+	// review запросили заново — отсчёт с начала
+	if !rem.RequestedAt.Equal(rv.RequestedAt) {
+		*rem = repository.ReviewReminder{
+			ID: rem.ID, Repo: pr.Repo, Number: pr.Number, Reviewer: rem.Reviewer, ReviewerID: rem.ReviewerID, RequestedAt: rv.RequestedAt,
+		}
+	}
+
+	waited := r.cfg.Calendar.WorkingDuration(rv.RequestedAt, now)
+	remind := rule.RemindAfter > 0 && waited >= rule.RemindAfter && rem.RemindedAt.IsZero() && !now.Before(rem.SnoozedUntil)
+	overdue := rule.EscalateAfter > 0 && waited >= rule.EscalateAfter
+	escalateAuthor := overdue && rem.EscalatedAt.IsZero()
+	escalateLead := overdue && rule.LeadChatID != 0 && rem.LeadEscalatedAt.IsZero()
+	if !remind && !escalateAuthor && !escalateLead {
+		if renamed {
+			if _, err := r.reminders.SaveReminder(*rem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// ID нужен для кнопки до отправки, поэтому запись сохраняется заранее
+	if rem.ID == 0 {
+		if rem.ID, err = r.reminders.SaveReminder(*rem); err != nil {
+			return err
+		}
+	}
+
+	ref := refOf(pr)
+	reviewer := GitHubUser{ID: rv.UserID, Login: rv.Login}
+	var errs []error
+	if remind {
+		err := r.notifier.NotifyAssignee(reviewer, Notification{
+			Kind:    EventReviewReminder,
+			PR:      ref,
+			Key:     "notify.reminder",
+			Args:    reminderArgs(ref, waited),
+			Buttons: [][]Button{{{Key: "button.snooze", Data: ReminderSnoozePrefix + strconv.FormatInt(rem.ID, 10)}}},
+		})
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			rem.RemindedAt = now
+		}
+	}
+	if escalateAuthor || escalateLead {
+		args := reminderArgs(ref, waited)
+		args["Reviewer"] = rv.Login
+		// каждому получателю эскалация уходит один раз: при сбое повторяется только ему
+		if escalateAuthor {
+			if err := r.escalateAuthor(pr, ref, args); err != nil {
+				errs = append(errs, err)
+			} else {
+				rem.EscalatedAt = now
+			}
+		}
+		if escalateLead {
+			if err := r.sender.SendMessage(rule.LeadChatID, r.notifier.Text(rule.LeadChatID, "notify.escalation", args)); err != nil {
+				errs = append(errs, fmt.Errorf("notify lead chat %d: %w", rule.LeadChatID, err))
+			} else {
+				rem.LeadEscalatedAt = now
+			}
+		}
+	}
+
+	if _, err := r.reminders.SaveReminder(*rem); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Reminders) escalateAuthor(pr repository.PullRequest, ref PullRequestRef, args i18n.Args) error {
+	author := GitHubUser{ID: pr.AuthorID, Login: pr.AuthorLogin}
+	if author.Login == "" && author.ID == 0 {
+		return nil
+	}
+	if err := r.notifier.NotifyAssignee(author, Notification{Kind: EventReviewReminder, PR: ref, Key: "notify.escalation", Args: args}); err != nil {
+		return fmt.Errorf("notify author: %w", err)
+	}
+	return nil
+}
+
+// Snooze откладывает напоминание: повторно оно придёт не раньше, чем через cfg.Snooze.
+func (r *Reminders) Snooze(id int64, now time.Time) (time.Time, error) {
+	rem, err := r.reminders.GetReminderByID(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	rem.SnoozedUntil = now.Add(r.cfg.Snooze)
+	rem.RemindedAt = time.Time{}
+	if _, err := r.reminders.SaveReminder(*rem); err != nil {
+		return time.Time{}, err
+	}
+	return rem.SnoozedUntil, nil
+}
+
+func (r *Reminders) rule(repo string) ReminderRule {
+	if rule, ok := r.cfg.Repos[strings.ToLower(repo)]; ok {
+		return rule
+	}
+	return r.cfg.Default
+}
+
+// reminderArgs — параметры текстов напоминания; waited — сколько рабочего времени ждёт review.
+func reminderArgs(ref PullRequestRef, waited time.Duration) i18n.Args {
+	return i18n.Args{
+		"Minutes": int(waited / time.Minute),
+		"Hours":   int(waited / time.Hour),
+		"Repo":    ref.Repo,
+		"Number":  ref.Number,
+		"Title":   ref.Title,
+		"URL":     ref.URL,
+	}
+}
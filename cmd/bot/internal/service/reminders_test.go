@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+func TestReminders_RemindEscalateAndSnooze(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	prs := memory.NewPullRequestRepo()
+	store := memory.NewReminderRepo()
+
+	// пятница 10:00, рабочие часы 10-18 по будням
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	err := prs.SavePullRequest(repository.PullRequest{
+		Repo: "org/repo", Number: 7, Title: "Fix", URL: "https://github.com/org/repo/pull/7",
+		AuthorLogin: "author", State: repository.PRStateOpen,
+		Reviewers: []repository.PullRequestReviewer{{Login: "reviewer", Requested: true, RequestedAt: requested}},
+	})
+	if err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+
+	cal, err := NewWorkCalendar("UTC", []string{"mon", "tue", "wed", "thu", "fri"}, "10:00-18:00", nil)
+	if err != nil {
+		t.Fatalf("NewWorkCalendar: %v", err)
+	}
+	lead := int64(99)
+	rem := NewReminders(prs, store, svc, sender, ReminderConfig{
+		Default:  ReminderRule{RemindAfter: 4 * time.Hour, EscalateAfter: 10 * time.Hour, LeadChatID: lead},
+		Calendar: cal,
+		Snooze:   time.Hour,
+	})
+
+	// 3 рабочих часа — рано
+	if err := rem.Check(requested.Add(3 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 0 {
+		t.Fatalf("expected no reminder yet, got %v", sender.sent[2])
+	}
+
+	// 5 рабочих часов — напоминание ревьюеру, один раз
+	at := requested.Add(5 * time.Hour)
+	for i := 0; i < 2; i++ {
+		if err := rem.Check(at); err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+	}
+	if len(sender.sent[2]) != 1 {
+		t.Fatalf("expected one reminder, got %v", sender.sent[2])
+	}
+
+	// выходные не считаются: в понедельник 11:00 прошло 9 рабочих часов, эскалации ещё нет
+	monday := time.Date(2024, 3, 4, 11, 0, 0, 0, time.UTC)
+	if err := rem.Check(monday); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[1]) != 0 || len(sender.sent[lead]) != 0 {
+		t.Fatalf("expected no escalation yet, got author=%v lead=%v", sender.sent[1], sender.sent[lead])
+	}
+
+	// отложили — напоминание повторится после snooze
+	r, err := store.GetReminder("org/repo", 7, 0, "reviewer")
+	if err != nil {
+		t.Fatalf("GetReminder: %v", err)
+	}
+	until, err := rem.Snooze(r.ID, monday)
+	if err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+	if !until.Equal(monday.Add(time.Hour)) {
+		t.Fatalf("unexpected snooze until %v", until)
+	}
+	if err := rem.Check(monday.Add(30 * time.Minute)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 1 {
+		t.Fatalf("expected reminder to be snoozed, got %v", sender.sent[2])
+	}
+
+	// 10+ рабочих часов: повторное напоминание и эскалация автору и лиду
+	if err := rem.Check(monday.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 2 {
+		t.Fatalf("expected reminder after snooze, got %v", sender.sent[2])
+	}
+	if len(sender.sent[1]) != 1 || len(sender.sent[lead]) != 1 {
+		t.Fatalf("expected escalation, got author=%v lead=%v", sender.sent[1], sender.sent[lead])
+	}
+}
+
+func TestReminders_RerequestResetsAndNightIsQuiet(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	prs := memory.NewPullRequestRepo()
+
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	pr := repository.PullRequest{
+		Repo: "org/repo", Number: 7, AuthorLogin: "author", State: repository.PRStateOpen,
+		Reviewers: []repository.PullRequestReviewer{{Login: "reviewer", Requested: true, RequestedAt: requested}},
+	}
+	if err := prs.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+
+	cal, err := NewWorkCalendar("UTC", nil, "09:00-18:00", nil)
+	if err != nil {
+		t.Fatalf("NewWorkCalendar: %v", err)
+	}
+	rem := NewReminders(prs, memory.NewReminderRepo(), svc, sender, ReminderConfig{
+		Repos:    map[string]ReminderRule{"Org/Repo": {RemindAfter: time.Hour}},
+		Calendar: cal,
+	})
+
+	// ночью не напоминаем
+	if err := rem.Check(requested.Add(12 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 0 {
+		t.Fatalf("expected no reminder at night, got %v", sender.sent[2])
+	}
+
+	if err := rem.Check(requested.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	// review запросили заново — ждём снова час
+	pr.Reviewers[0].RequestedAt = requested.Add(3 * time.Hour)
+	if err := prs.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+	if err := rem.Check(requested.Add(3*time.Hour + 30*time.Minute)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if err := rem.Check(requested.Add(4*time.Hour + 30*time.Minute)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 2 {
+		t.Fatalf("expected two reminders, got %v", sender.sent[2])
+	}
+}
+
+// flakySender отказывает в первой отправке в чат chatID.
+type flakySender struct {
+	*senderMock
+	chatID int64
+	failed bool
+}
+
+func (s *flakySender) SendMessage(chatID int64, text string, buttons ...[]Button) error {
+	if chatID == s.chatID && !s.failed {
+		s.failed = true
+		return errors.New("boom")
+	}
+	return s.senderMock.SendMessage(chatID, text, buttons...)
+}
+
+func TestReminders_LeadChatFailureDoesNotRepeatAuthorEscalation(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	prs := memory.NewPullRequestRepo()
+
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	err := prs.SavePullRequest(repository.PullRequest{
+		Repo: "org/repo", Number: 7, AuthorLogin: "author", State: repository.PRStateOpen,
+		Reviewers: []repository.PullRequestReviewer{{Login: "reviewer", Requested: true, RequestedAt: requested}},
+	})
+	if err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+	cal, err := NewWorkCalendar("UTC", nil, "09:00-18:00", nil)
+	if err != nil {
+		t.Fatalf("NewWorkCalendar: %v", err)
+	}
+	lead := int64(99)
+	rem := NewReminders(prs, memory.NewReminderRepo(), svc, &flakySender{senderMock: sender, chatID: lead}, ReminderConfig{
+		Default:  ReminderRule{EscalateAfter: time.Hour, LeadChatID: lead},
+		Calendar: cal,
+	})
+
+	if err := rem.Check(requested.Add(2 * time.Hour)); err == nil {
+		t.Fatalf("expected lead chat error")
+	}
+	if err := rem.Check(requested.Add(3 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if err := rem.Check(requested.Add(4 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[1]) != 1 || len(sender.sent[lead]) != 1 {
+		t.Fatalf("expected one escalation each, got author=%v lead=%v", sender.sent[1], sender.sent[lead])
+	}
+}
+
+func TestReminders_DefaultSnooze(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	store := memory.NewReminderRepo()
+	id, err := store.SaveReminder(repository.ReviewReminder{Repo: "org/repo", Number: 7, Reviewer: "reviewer"})
+	if err != nil {
+		t.Fatalf("SaveReminder: %v", err)
+	}
+	rem := NewReminders(memory.NewPullRequestRepo(), store, svc, sender, ReminderConfig{})
+
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	until, err := rem.Snooze(id, now)
+	if err != nil {
+		t.Fatalf("Snooze: %v", err)
+	}
+	if !until.Equal(now.Add(DefaultReminderSnooze)) {
+		t.Fatalf("unexpected snooze until %v", until)
+	}
+}
+
+func TestReminders_ReviewerRenameDoesNotRestart(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+	prs := memory.NewPullRequestRepo()
+	store := memory.NewReminderRepo()
+
+	requested := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	pr := repository.PullRequest{
+		Repo: "org/repo", Number: 7, AuthorLogin: "author", State: repository.PRStateOpen,
+		Reviewers: []repository.PullRequestReviewer{{Login: "reviewer", UserID: 20, Requested: true, RequestedAt: requested}},
+	}
+	if err := prs.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+	cal, err := NewWorkCalendar("UTC", nil, "09:00-18:00", nil)
+	if err != nil {
+		t.Fatalf("NewWorkCalendar: %v", err)
+	}
+	rem := NewReminders(prs, store, svc, sender, ReminderConfig{
+		Default:  ReminderRule{RemindAfter: time.Hour},
+		Calendar: cal,
+	})
+
+	if err := rem.Check(requested.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(sender.sent[2]) != 1 {
+		t.Fatalf("expected one reminder, got %v", sender.sent[2])
+	}
+	first, err := store.GetReminder("org/repo", 7, 20, "reviewer")
+	if err != nil {
+		t.Fatalf("GetReminder: %v", err)
+	}
+
+	// ревьюер переименовался — это тот же человек и то же напоминание
+	pr.Reviewers[0].Login = "reviewer-new"
+	if err := prs.SavePullRequest(pr); err != nil {
+		t.Fatalf("SavePullRequest: %v", err)
+	}
+	if err := rem.Check(requested.Add(3 * time.Hour)); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	got, err := store.GetReminder("org/repo", 7, 20, "reviewer-new")
+	if err != nil {
+		t.Fatalf("GetReminder: %v", err)
+	}
+	if got.ID != first.ID || got.Reviewer != "reviewer-new" || !got.RemindedAt.Equal(first.RemindedAt) {
+		t.Fatalf("expected the same reminder under the new login, got %+v (was %+v)", got, first)
+	}
+	if _, err := store.GetReminder("org/repo", 7, 0, "reviewer"); err != repository.ErrNotFound {
+		t.Fatalf("expected old login to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/github"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+var (
+	// ErrNotReplyable — сообщение, на которое ответили, не привязано к review comment.
+	ErrNotReplyable = errors.New("message is not a review comment notification")
+	// ErrNoGitHubToken — пользователь не подтвердил аккаунт через /link, писать в GitHub нечем.
+	ErrNoGitHubToken = errors.New("github account is not linked")
+	// ErrNoGitHubScope — токен из /link выдан без scope repo: /link повторяют после того,
+	// как repo добавлен в github.oauth.scope, иначе GitHub снова выдаст тот же scope.
+	ErrNoGitHubScope = errors.New("github token lacks repo scope")
+)
+
+// ReviewCommenter — часть GitHub API для ответов в треде review comment.
+type ReviewCommenter interface {
+	ReplyToReviewComment(ctx context.Context, token, repo string, number int, commentID int64, body string) error
+}
+
+// Replier публикует ответы из Telegram в треды review comments на GitHub.
+type Replier struct {
+	messages    repository.MessageRepository
+	credentials repository.CredentialsRepository
+	github      ReviewCommenter
+	timeout     time.Duration
+}
+
+func NewReplier(messages repository.MessageRepository, credentials repository.CredentialsRepository, gh ReviewCommenter) *Replier {
+	return &Replier{messages: messages, credentials: credentials, github: gh, timeout: 15 * time.Second}
+}
+
+// Reply отправляет text ответом на review comment, о котором было сообщение messageID в чате chatID.
+// Возвращает PR, в который ушёл ответ.
+func (r *Replier) Reply(chatID int64, messageID int, text string) (PullRequestRef, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return PullRequestRef{}, fmt.Errorf("empty reply")
+	}
+
+	sent, err := r.messages.GetSentMessage(chatID, messageID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return PullRequestRef{}, ErrNotReplyable
+	}
+	if err != nil {
+		return PullRequestRef{}, fmt.Errorf("get sent message: %w", err)
+	}
+	if sent.Ref.CommentID == 0 || sent.Ref.Repo == "" || sent.Ref.Number == 0 {
+		return PullRequestRef{}, ErrNotReplyable
+	}
+
+	token, err := githubToken(r.credentials, chatID)
+	if err != nil {
+		return PullRequestRef{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	ref := sent.Ref
+	if err := r.github.ReplyToReviewComment(ctx, token, ref.Repo, ref.Number, ref.CommentID, text); err != nil {
+		return PullRequestRef{}, scopeError(err)
+	}
+	return PullRequestRef{Repo: ref.Repo, Number: ref.Number}, nil
+}
+
+// scopeError превращает отказ GitHub из-за scope токена в ErrNoGitHubScope.
+func scopeError(err error) error {
+	if errors.Is(err, github.ErrInsufficientScope) {
+		return fmt.Errorf("%w: %v", ErrNoGitHubScope, err)
+	}
+	return err
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository/memory"
+)
+
+type commenterMock struct {
+	replies []string
+}
+
+func (m *commenterMock) ReplyToReviewComment(_ context.Context, token, repo string, number int, commentID int64, body string) error {
+	m.replies = append(m.replies, fmt.Sprintf("%s %s#%d/%d %s", token, repo, number, commentID, body))
+	return nil
+}
+
+// idSender отдаёт возрастающие ID сообщений, как Telegram.
+type idSender struct {
+	senderMock
+	next int
+}
+
+func (s *idSender) SendMessageID(chatID int64, text string, buttons ...[]Button) (int, error) {
+	s.next++
+	return s.next, s.SendMessage(chatID, text, buttons...)
+}
+
+func TestReplier_RepliesThroughRecordedMessage(t *testing.T) {
+	users := memory.NewUserRepo()
+	if err := users.SaveBinding(repository.UserBinding{TelegramID: 1, GitHubLogin: "author"}); err != nil {
+		t.Fatalf("SaveBinding: %v", err)
+	}
+	outbox := memory.NewOutboxRepo()
+	messages := memory.NewMessageRepo()
+	svc := NewNotifier(users, memory.NewSettingsRepo(), nil, nil, nil, NewOutboxSender(outbox), Config{})
+
+	n := Notification{
+		Kind:      EventReviewComment,
+		PR:        PullRequestRef{Repo: "org/repo", Number: 5},
+		Text:      "comment",
+		CommentID: 77,
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, n); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+	if err := svc.NotifyAssignee(GitHubUser{Login: "author"}, Notification{PR: n.PR, Text: "plain"}); err != nil {
+		t.Fatalf("NotifyAssignee: %v", err)
+	}
+
+	sender := &idSender{}
+	w := NewOutboxWorker(outbox, messages, sender, OutboxConfig{}, log.New(io.Discard, "", 0))
+	w.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, err := w.ProcessBatch(); err != nil {
+		t.Fatalf("ProcessBatch: %v", err)
+	}
+
+	gh := &commenterMock{}
+	creds := memory.NewCredentialsRepo()
+	r := NewReplier(messages, creds, gh)
+
+	if _, err := r.Reply(1, 1, "fixed"); !errors.Is(err, ErrNoGitHubToken) {
+		t.Fatalf("expected ErrNoGitHubToken, got %v", err)
+	}
+	if err := creds.SaveCredentials(repository.GitHubCredentials{TelegramID: 1, AccessToken: "tok"}); err != nil {
+		t.Fatalf("SaveCredentials: %v", err)
+	}
+
+	ref, err := r.Reply(1, 1, "fixed")
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	if ref.Repo != "org/repo" || ref.Number != 5 {
+		t.Fatalf("unexpected ref %+v", ref)
+	}
+	if len(gh.replies) != 1 || gh.replies[0] != "tok org/repo#5/77 fixed" {
+		t.Fatalf("unexpected replies: %v", gh.replies)
+	}
+
+	// второе сообщение не про review comment
+	if _, err := r.Reply(1, 2, "fixed"); !errors.Is(err, ErrNotReplyable) {
+		t.Fatalf("expected ErrNotReplyable, got %v", err)
+	}
+	if _, err := r.Reply(1, 99, "fixed"); !errors.Is(err, ErrNotReplyable) {
+		t.Fatalf("expected ErrNotReplyable for unknown message, got %v", err)
+	}
+}
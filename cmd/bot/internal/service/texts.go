@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/repository"
+)
+
+// Translator отдаёт текст бота на языке пользователя.
+type Translator interface {
+	Text(tgID int64, key string, args i18n.Args) string
+}
+
+// catalogTexts — Translator без настроек пользователей: всё на языке по умолчанию.
+type catalogTexts struct {
+	texts *i18n.Catalog
+}
+
+func (t catalogTexts) Text(_ int64, key string, args i18n.Args) string {
+	return t.texts.Text(t.texts.DefaultLang(), key, args)
+}
+
+func translatorOrDefault(t Translator) Translator {
+	if t == nil {
+		return catalogTexts{texts: i18n.Default()}
+	}
+	return t
+}
+
+// Lang возвращает язык пользователя; если он не выбран или тексты для него пропали — язык по умолчанию.
+func (s *Notifier) Lang(tgID int64) (string, error) {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return "", err
+	}
+	return s.lang(st), nil
+}
+
+// SetLang сохраняет язык сообщений пользователя.
+func (s *Notifier) SetLang(tgID int64, lang string) error {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if !s.texts.Supported(lang) {
+		return fmt.Errorf("unsupported language %q", lang)
+	}
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return err
+	}
+	st.Lang = lang
+	return s.settings.SaveSettings(st)
+}
+
+// Langs — языки, для которых есть тексты.
+func (s *Notifier) Langs() []string {
+	return s.texts.Langs()
+}
+
+// Text возвращает текст key на языке пользователя. Если настройки не прочитать,
+// текст всё равно нужен — берётся язык по умолчанию.
+func (s *Notifier) Text(tgID int64, key string, args i18n.Args) string {
+	st, err := s.settings.GetSettings(tgID)
+	if err != nil {
+		return s.texts.Text(s.texts.DefaultLang(), key, args)
+	}
+	return s.text(st, key, args)
+}
+
+func (s *Notifier) text(st repository.UserSettings, key string, args i18n.Args) string {
+	return s.texts.Text(s.lang(st), key, args)
+}
+
+func (s *Notifier) lang(st repository.UserSettings) string {
+	if st.Lang != "" && s.texts.Supported(st.Lang) {
+		return st.Lang
+	}
+	return s.texts.DefaultLang()
+}
+
+// localize собирает текст и подписи кнопок уведомления на языке получателя.
+func (s *Notifier) localize(st repository.UserSettings, n Notification) Notification {
+	if n.Key != "" {
+		args := make(i18n.Args, len(n.Args)+1)
+		for k, v := range n.Args {
+			args[k] = v
+		}
+		args["Own"] = n.ForAuthor
+		n.Text = s.text(st, n.Key, args)
+		n.Key, n.Args = "", nil
+	}
+	n.Buttons = s.localizeButtons(st, n.Buttons)
+	return n
+}
+
+// localizeButtons копирует ряды: одно уведомление уходит нескольким получателям.
+func (s *Notifier) localizeButtons(st repository.UserSettings, rows [][]Button) [][]Button {
+	if len(rows) == 0 {
+		return rows
+	}
+	out := make([][]Button, len(rows))
+	for i, row := range rows {
+		out[i] = make([]Button, len(row))
+		for j, b := range row {
+			if b.Key != "" {
+				b.Text = s.text(st, b.Key, nil)
+				b.Key = ""
+			}
+			out[i][j] = b
+		}
+	}
+	return out
+}
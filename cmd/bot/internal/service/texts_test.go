@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/i18n"
+)
+
+func TestNotifier_RendersInUserLang(t *testing.T) {
+	svc, sender := newTestNotifier(t, Config{})
+
+	if err := svc.SetLang(1, "EN"); err != nil {
+		t.Fatalf("SetLang: %v", err)
+	}
+	if lang, err := svc.Lang(1); err != nil || lang != "en" {
+		t.Fatalf("expected lang en, got %q (%v)", lang, err)
+	}
+
+	n := Notification{
+		Kind: EventApproved,
+		Key:  "notify.approved",
+		Args: i18n.Args{"Title": "Fix", "URL": "https://github.com/org/repo/pull/5"},
+	}
+	for _, login := range []string{"author", "reviewer"} {
+		if err := svc.NotifyAssignee(GitHubUser{Login: login}, n); err != nil {
+			t.Fatalf("NotifyAssignee: %v", err)
+		}
+	}
+
+	if len(sender.sent[1]) != 1 || sender.sent[1][0] != "The PR was approved: Fix — https://github.com/org/repo/pull/5" {
+		t.Fatalf("expected english text, got %v", sender.sent[1])
+	}
+	if len(sender.sent[2]) != 1 || sender.sent[2][0] != "PR одобрен: Fix — https://github.com/org/repo/pull/5" {
+		t.Fatalf("expected russian text by default, got %v", sender.sent[2])
+	}
+}
+
+func TestNotifier_SetLangUnsupported(t *testing.T) {
+	svc, _ := newTestNotifier(t, Config{})
+
+	if err := svc.SetLang(1, "xx"); err == nil {
+		t.Fatalf("expected error for unsupported language")
+	}
+	if lang, err := svc.Lang(1); err != nil || lang != i18n.DefaultLang {
+		t.Fatalf("expected default lang, got %q (%v)", lang, err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	_ "time/tzdata" // часовые пояса для /quiet в образе без системной tzdata
 
 	"github.com/andrewpolewoy/go_bot/cmd/bot/internal/app"
 )
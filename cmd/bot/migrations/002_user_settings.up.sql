@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS user_settings (
+  telegram_id BIGINT PRIMARY KEY,
+  self_notify BOOLEAN
+);
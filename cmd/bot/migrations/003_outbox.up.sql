@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id              BIGSERIAL PRIMARY KEY,
+  chat_id         BIGINT NOT NULL,
+  text            TEXT NOT NULL,
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_error      TEXT NOT NULL DEFAULT '',
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt_at ON outbox (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS outbox_dead_letters (
+  id         BIGINT PRIMARY KEY,
+  chat_id    BIGINT NOT NULL,
+  text       TEXT NOT NULL,
+  attempts   INT NOT NULL,
+  last_error TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL,
+  failed_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id         TEXT PRIMARY KEY,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_expires_at ON webhook_deliveries (expires_at);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS github_credentials;
+
+ALTER TABLE user_bindings DROP COLUMN IF EXISTS verified;
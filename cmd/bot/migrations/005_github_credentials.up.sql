@@ -0,0 +1,9 @@
+ALTER TABLE user_bindings ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS github_credentials (
+  telegram_id    BIGINT PRIMARY KEY,
+  github_user_id BIGINT NOT NULL,
+  github_login   TEXT NOT NULL,
+  access_token   TEXT NOT NULL,
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
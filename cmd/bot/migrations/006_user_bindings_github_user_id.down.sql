@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_bindings_github_user_id;
+
+ALTER TABLE user_bindings DROP COLUMN IF EXISTS github_user_id;
@@ -0,0 +1,11 @@
+ALTER TABLE user_bindings ADD COLUMN IF NOT EXISTS github_user_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_user_bindings_github_user_id ON user_bindings (github_user_id);
+
+-- подтверждённые через /link привязки уже знают свой ID
+UPDATE user_bindings b
+SET github_user_id = c.github_user_id
+FROM github_credentials c
+WHERE c.telegram_id = b.telegram_id
+  AND c.github_login = b.github_login
+  AND b.github_user_id IS NULL;
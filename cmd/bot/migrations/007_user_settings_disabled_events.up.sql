@@ -0,0 +1 @@
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS disabled_events TEXT[] NOT NULL DEFAULT '{}';
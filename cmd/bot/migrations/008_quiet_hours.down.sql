@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS held_notifications;
+
+ALTER TABLE user_settings DROP COLUMN IF EXISTS quiet_end;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS quiet_start;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,13 @@
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS quiet_start INT;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS quiet_end INT;
+
+CREATE TABLE IF NOT EXISTS held_notifications (
+  id          BIGSERIAL PRIMARY KEY,
+  telegram_id BIGINT NOT NULL,
+  text        TEXT NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  release_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_held_notifications_release_at ON held_notifications (release_at);
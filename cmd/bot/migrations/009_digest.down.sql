@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS digest_events;
+
+ALTER TABLE user_settings DROP COLUMN IF EXISTS digest_time;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS digest_enabled;
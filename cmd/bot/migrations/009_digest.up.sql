@@ -0,0 +1,17 @@
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS digest_time INT;
+
+CREATE TABLE IF NOT EXISTS digest_events (
+  id          BIGSERIAL PRIMARY KEY,
+  telegram_id BIGINT NOT NULL,
+  kind        TEXT NOT NULL,
+  repo        TEXT NOT NULL DEFAULT '',
+  number      INT NOT NULL DEFAULT 0,
+  title       TEXT NOT NULL DEFAULT '',
+  url         TEXT NOT NULL DEFAULT '',
+  actor       TEXT NOT NULL DEFAULT '',
+  text        TEXT NOT NULL,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digest_events_telegram_id ON digest_events (telegram_id, created_at);
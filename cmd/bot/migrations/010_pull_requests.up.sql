@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS pull_requests (
+  repo         TEXT NOT NULL,
+  number       INT NOT NULL,
+  github_id    BIGINT NOT NULL DEFAULT 0,
+  title        TEXT NOT NULL DEFAULT '',
+  url          TEXT NOT NULL DEFAULT '',
+  author_login TEXT NOT NULL DEFAULT '',
+  author_id    BIGINT NOT NULL DEFAULT 0,
+  assignees    TEXT[] NOT NULL DEFAULT '{}',
+  draft        BOOLEAN NOT NULL DEFAULT false,
+  state        TEXT NOT NULL DEFAULT 'open',
+  head_sha     TEXT NOT NULL DEFAULT '',
+  mergeable    BOOLEAN,
+  created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (repo, number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_state ON pull_requests (state);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_head_sha ON pull_requests (head_sha);
+
+CREATE TABLE IF NOT EXISTS pull_request_reviewers (
+  repo         TEXT NOT NULL,
+  number       INT NOT NULL,
+  login        TEXT NOT NULL,
+  user_id      BIGINT NOT NULL DEFAULT 0,
+  requested    BOOLEAN NOT NULL DEFAULT false,
+  requested_at TIMESTAMPTZ,
+  state        TEXT NOT NULL DEFAULT '',
+  reviewed_at  TIMESTAMPTZ,
+  PRIMARY KEY (repo, number, login),
+  FOREIGN KEY (repo, number) REFERENCES pull_requests (repo, number) ON DELETE CASCADE
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS review_reminders;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS buttons;
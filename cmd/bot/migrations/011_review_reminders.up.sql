@@ -0,0 +1,13 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS buttons JSONB NOT NULL DEFAULT '[]';
+
+CREATE TABLE IF NOT EXISTS review_reminders (
+  id            BIGSERIAL PRIMARY KEY,
+  repo          TEXT NOT NULL,
+  number        INT NOT NULL,
+  reviewer      TEXT NOT NULL,
+  requested_at  TIMESTAMPTZ NOT NULL,
+  reminded_at   TIMESTAMPTZ,
+  escalated_at  TIMESTAMPTZ,
+  snoozed_until TIMESTAMPTZ,
+  UNIQUE (repo, number, reviewer)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sent_messages;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS ref;
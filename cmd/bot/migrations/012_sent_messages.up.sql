@@ -0,0 +1,14 @@
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS ref JSONB;
+
+CREATE TABLE IF NOT EXISTS sent_messages (
+  chat_id    BIGINT NOT NULL,
+  message_id BIGINT NOT NULL,
+  repo       TEXT NOT NULL DEFAULT '',
+  number     INT NOT NULL DEFAULT 0,
+  kind       TEXT NOT NULL DEFAULT '',
+  comment_id BIGINT NOT NULL DEFAULT 0,
+  sent_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (chat_id, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sent_messages_sent_at ON sent_messages (sent_at);
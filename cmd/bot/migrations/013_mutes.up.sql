@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS mutes (
+  telegram_id BIGINT NOT NULL,
+  target      TEXT NOT NULL,
+  expires_at  TIMESTAMPTZ,
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (telegram_id, target)
+);
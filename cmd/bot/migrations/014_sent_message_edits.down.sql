@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_sent_messages_pr;
+
+ALTER TABLE sent_messages
+  DROP COLUMN IF EXISTS edited_at,
+  DROP COLUMN IF EXISTS text;
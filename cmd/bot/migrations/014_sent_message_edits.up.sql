@@ -0,0 +1,5 @@
+ALTER TABLE sent_messages
+  ADD COLUMN IF NOT EXISTS text TEXT NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_sent_messages_pr ON sent_messages (repo, number);
@@ -0,0 +1 @@
+ALTER TABLE review_reminders DROP COLUMN IF EXISTS lead_escalated_at;
@@ -0,0 +1 @@
+ALTER TABLE review_reminders ADD COLUMN IF NOT EXISTS lead_escalated_at TIMESTAMPTZ;
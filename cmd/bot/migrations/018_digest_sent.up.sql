@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS digest_sent (
+  telegram_id BIGINT PRIMARY KEY,
+  slot        TIMESTAMPTZ NOT NULL
+);
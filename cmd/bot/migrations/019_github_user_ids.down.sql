@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS uq_review_reminders_reviewer;
+DROP INDEX IF EXISTS uq_review_reminders_reviewer_id;
+ALTER TABLE review_reminders DROP COLUMN IF EXISTS reviewer_id;
+ALTER TABLE review_reminders ADD CONSTRAINT review_reminders_repo_number_reviewer_key UNIQUE (repo, number, reviewer);
+
+DROP INDEX IF EXISTS uq_pull_request_reviewers_login;
+DROP INDEX IF EXISTS uq_pull_request_reviewers_user_id;
+ALTER TABLE pull_request_reviewers ADD PRIMARY KEY (repo, number, login);
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS assignee_ids;
@@ -0,0 +1,15 @@
+-- PR state and reminders follow GitHub user IDs, so a rename keeps the reviewer
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS assignee_ids BIGINT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE pull_request_reviewers DROP CONSTRAINT IF EXISTS pull_request_reviewers_pkey;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pull_request_reviewers_user_id
+  ON pull_request_reviewers (repo, number, user_id) WHERE user_id <> 0;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pull_request_reviewers_login
+  ON pull_request_reviewers (repo, number, login) WHERE user_id = 0;
+
+ALTER TABLE review_reminders ADD COLUMN IF NOT EXISTS reviewer_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE review_reminders DROP CONSTRAINT IF EXISTS review_reminders_repo_number_reviewer_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_review_reminders_reviewer_id
+  ON review_reminders (repo, number, reviewer_id) WHERE reviewer_id <> 0;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_review_reminders_reviewer
+  ON review_reminders (repo, number, reviewer) WHERE reviewer_id = 0;